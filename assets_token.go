@@ -1,6 +1,7 @@
 package corbel
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -9,6 +10,12 @@ import (
 // UpgradeToken returns the assertion to upgrade the token on IAM to get the
 // 'purchased' scopes
 func (a *AssetsService) UpgradeToken() error {
+	return a.UpgradeTokenContext(context.Background())
+}
+
+// UpgradeTokenContext is like UpgradeToken but uses ctx for all the requests
+// involved.
+func (a *AssetsService) UpgradeTokenContext(ctx context.Context) error {
 	var (
 		req      *http.Request
 		res      *http.Response
@@ -16,27 +23,31 @@ func (a *AssetsService) UpgradeToken() error {
 		err      error
 	)
 
-	req, err = a.client.NewRequest("GET", "assets", "/v1.0/asset/access", nil)
+	req, err = a.client.NewRequestContext(ctx, "GET", "assets", "/v1.0/asset/access", nil)
 	if err != nil {
 		return err
 	}
 
 	req.Header.Add("No-Redirect", "true")
-	res, err = a.client.httpClient.Do(req)
+	res, err = a.client.do(req)
 	if err != nil {
 		return err
 	}
+	res.Body.Close()
 
 	location, err = res.Location()
 	if err != nil {
 		return err
 	}
 
-	req, err = a.client.NewRequest("GET", "iam", fmt.Sprintf("/v1.0/oauth/token/upgrade?%s", location.RawQuery), nil)
+	req, err = a.client.NewRequestContext(ctx, "GET", "iam", fmt.Sprintf("/v1.0/oauth/token/upgrade?%s", location.RawQuery), nil)
 	if err != nil {
 		return err
 	}
 
-	_, err = returnErrorHTTPSimple(a.client, req, err, 204)
-	return err
+	if _, err = returnErrorHTTPSimple(a.client, req, err, 204); err != nil {
+		return err
+	}
+	a.client.tokenUpgraded()
+	return nil
 }
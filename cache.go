@@ -0,0 +1,296 @@
+package corbel
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheSize is the number of responses kept by the cache used by
+// WithCache when no store is given
+const DefaultCacheSize = 1000
+
+// CacheStore stores the responses cached by a Client. Keys are the URL of the
+// request followed by the scope of its token, so clients with different
+// tokens can share a store. Implementations must be safe for concurrent use.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	Delete(key string)
+	// DeletePrefix deletes the responses whose key starts with prefix. It's
+	// used to remove the responses of a collection when it's modified.
+	DeletePrefix(prefix string)
+}
+
+// CachedResponse is a response stored in a CacheStore. It must not be
+// modified once stored.
+type CachedResponse struct {
+	// Scope identifies the token the response was got with. It's only used
+	// for requests with the same scope, so stores can be shared by clients.
+	Scope string
+	// Header is the header of the response
+	Header http.Header
+	// Body is the body of the response
+	Body []byte
+	// Expires is when the response stops being fresh and must be revalidated.
+	// The zero time means it must always be revalidated.
+	Expires time.Time
+}
+
+// fresh reports if the response can be used without revalidating it
+func (r *CachedResponse) fresh(now time.Time) bool {
+	return now.Before(r.Expires)
+}
+
+// response returns the cached response as an answer to req
+func (r *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// lruCache is a CacheStore in memory that evicts the least recently used
+// response once it's full
+type lruCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// lruEntry is an element of the order list of lruCache
+type lruEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewLRUCache returns a CacheStore in memory holding up to size responses.
+// Once full, the least recently used response is evicted.
+func NewLRUCache(size int) CacheStore {
+	if size < 1 {
+		size = 1
+	}
+	return &lruCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry).response, true
+}
+
+func (c *lruCache) Set(key string, response *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry).response = response
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, response: response})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+func (c *lruCache) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// cacheScope returns the scope of the responses got with the token of req. It
+// also counts the upgrades of the token, since they add scopes to it.
+func (c *Client) cacheScope(req *http.Request) string {
+	c.tokenMu.Lock()
+	upgrades := c.tokenUpgrades
+	c.tokenMu.Unlock()
+	return fmt.Sprintf("%x %d", sha256.Sum256([]byte(req.Header.Get("Authorization"))), upgrades)
+}
+
+// invalidateCache removes the cached responses that a write to u may change,
+// which are all the ones of its collection, like searches, relations and
+// aliases like /v1.0/user/me.
+func (c *Client) invalidateCache(u *url.URL) {
+	collection := url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host, Path: cacheCollectionPath(u.Path)}
+	key := collection.String()
+	for _, separator := range []string{"\x00", "/", "?", ";"} {
+		c.Cache.DeletePrefix(key + separator)
+	}
+}
+
+// cacheCollectionPath returns the path of the collection of the entity in
+// path, /v1.0/resource/{collection} for resources and /v1.0/{type} otherwise.
+func cacheCollectionPath(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	n := 2
+	if len(segments) > 1 && segments[1] == "resource" {
+		n = 3
+	}
+	if n > len(segments) {
+		n = len(segments)
+	}
+	return "/" + strings.Join(segments[:n], "/")
+}
+
+// doCached sends a GET request answering it from the cache if the cached
+// response is still fresh, and revalidating it otherwise.
+func (c *Client) doCached(req *http.Request) (*http.Response, error) {
+	uri, scope, now := req.URL.String(), c.cacheScope(req), time.Now()
+	key := cacheKey(uri, scope)
+
+	cached, ok := c.Cache.Get(key)
+	if ok && cached.Scope != scope {
+		cached, ok = nil, false
+	}
+	if ok && cached.fresh(now) {
+		c.logger.WithFields(Fields{"url": uri}).Debug("response got from cache")
+		return cached.response(req), nil
+	}
+	if ok {
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	res, err := c.doAuthenticated(req)
+	if err != nil {
+		return res, err
+	}
+	// the request may have been sent again with a new token
+	storeScope, storeKey := scope, key
+	if res.Request != nil {
+		if storeScope = c.cacheScope(res.Request); storeScope != scope {
+			storeKey = cacheKey(uri, storeScope)
+		}
+	}
+
+	if ok && res.StatusCode == http.StatusNotModified {
+		res.Body.Close()
+		revalidated := *cached
+		revalidated.Scope = storeScope
+		revalidated.Header = cached.Header.Clone()
+		for name, values := range res.Header {
+			revalidated.Header[name] = values
+		}
+		revalidated.Expires = cacheExpires(revalidated.Header, now)
+		c.Cache.Set(storeKey, &revalidated)
+		c.logger.WithFields(Fields{"url": uri}).Debug("cached response revalidated")
+		return revalidated.response(req), nil
+	}
+
+	if res.StatusCode != http.StatusOK || !cacheable(res.Header) {
+		if ok {
+			c.Cache.Delete(key)
+		}
+		return res, nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	c.Cache.Set(storeKey, &CachedResponse{
+		Scope:   storeScope,
+		Header:  res.Header.Clone(),
+		Body:    body,
+		Expires: cacheExpires(res.Header, now),
+	})
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
+
+// cacheKey returns the key of the response of uri got with scope. Keys start
+// by the uri, so the responses of a collection share a prefix.
+func cacheKey(uri, scope string) string {
+	return uri + "\x00" + scope
+}
+
+// cacheable reports if a response with header can be stored: it must not
+// forbid it and it must be fresh for a while or have a validator.
+func cacheable(header http.Header) bool {
+	directives := cacheControl(header)
+	if _, ok := directives["no-store"]; ok {
+		return false
+	}
+	return header.Get("ETag") != "" || header.Get("Last-Modified") != "" || !cacheExpires(header, time.Now()).IsZero()
+}
+
+// cacheExpires returns until when a response with header received at now is
+// fresh, or the zero time if it must always be revalidated
+func cacheExpires(header http.Header, now time.Time) time.Time {
+	directives := cacheControl(header)
+	if _, ok := directives["no-cache"]; ok {
+		return time.Time{}
+	}
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil || seconds <= 0 {
+			return time.Time{}
+		}
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if expires, err := http.ParseTime(header.Get("Expires")); err == nil && expires.After(now) {
+		return expires
+	}
+	return time.Time{}
+}
+
+// cacheControl returns the directives of the Cache-Control header
+func cacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+			}
+		}
+	}
+	return directives
+}
@@ -0,0 +1,283 @@
+package corbel
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCacheLRU(t *testing.T) {
+	cache := NewLRUCache(2)
+	a, b, c := &CachedResponse{Scope: "a"}, &CachedResponse{Scope: "b"}, &CachedResponse{Scope: "c"}
+
+	cache.Set("a", a)
+	cache.Set("b", b)
+	if got, ok := cache.Get("a"); !ok || got != a {
+		t.Errorf("Error getting a. Got: %v, %v", got, ok)
+	}
+	// b is the least recently used now
+	cache.Set("c", c)
+	if _, ok := cache.Get("b"); ok {
+		t.Error("The least recently used response must be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("A recently used response must not be evicted")
+	}
+
+	cache.Set("c", a)
+	if got, ok := cache.Get("c"); !ok || got != a {
+		t.Errorf("Set must replace the response. Got: %v, %v", got, ok)
+	}
+	cache.Delete("c")
+	if _, ok := cache.Get("c"); ok {
+		t.Error("A deleted response must not be got")
+	}
+
+	cache.Set("ab", b)
+	cache.DeletePrefix("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("A response deleted by prefix must not be got")
+	}
+	if _, ok := cache.Get("ab"); ok {
+		t.Error("A response deleted by prefix must not be got")
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	now := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		header    http.Header
+		expires   time.Time
+		cacheable bool
+	}{
+		{http.Header{}, time.Time{}, false},
+		{http.Header{"Etag": {`"v1"`}}, time.Time{}, true},
+		{http.Header{"Last-Modified": {"Sat, 02 Jan 2016 03:00:00 GMT"}}, time.Time{}, true},
+		{http.Header{"Cache-Control": {"private, max-age=60"}}, now.Add(time.Minute), true},
+		{http.Header{"Cache-Control": {"max-age=0"}, "Etag": {`"v1"`}}, time.Time{}, true},
+		{http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}}, time.Time{}, true},
+		{http.Header{"Cache-Control": {"no-store"}, "Etag": {`"v1"`}}, time.Time{}, false},
+		{http.Header{"Expires": {"Sat, 02 Jan 2016 04:04:05 GMT"}}, now.Add(time.Hour), true},
+		{http.Header{"Cache-Control": {"max-age=10"}, "Expires": {"Sat, 02 Jan 2016 04:04:05 GMT"}}, now.Add(10 * time.Second), true},
+	}
+
+	for _, test := range tests {
+		if got, want := cacheExpires(test.header, now), test.expires; !got.Equal(want) {
+			t.Errorf("Error in expiration of %v. Got: %v, Want: %v", test.header, got, want)
+		}
+		if test.header.Get("Expires") != "" {
+			continue
+		}
+		if got, want := cacheable(test.header), test.cacheable; got != want {
+			t.Errorf("Error in cacheable of %v. Got: %v, Want: %v", test.header, got, want)
+		}
+	}
+}
+
+func TestCacheOffline(t *testing.T) {
+	var (
+		requests    int
+		ifNoneMatch string
+		maxAge      string
+	)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		ifNoneMatch = r.Header.Get("If-None-Match")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", maxAge)
+		if ifNoneMatch == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"count":1}`))
+	}))
+	client.Cache = NewLRUCache(10)
+
+	get := func() {
+		t.Helper()
+		var counter etagTestCounter
+		if err := client.Resources.GetFromCollection("test:Counter", "1", &counter); err != nil {
+			t.Fatalf("GetFromCollection returned error: %v", err)
+		}
+		if got, want := counter.Count, 1; got != want {
+			t.Errorf("Error in resource got. Got: %v, Want: %v", got, want)
+		}
+	}
+
+	maxAge = "no-cache"
+	get()
+	if requests != 1 || ifNoneMatch != "" {
+		t.Errorf("First request must not be conditional. Got: %v requests, If-None-Match %q", requests, ifNoneMatch)
+	}
+	get()
+	if requests != 2 || ifNoneMatch != `"v1"` {
+		t.Errorf("A cached response must be revalidated. Got: %v requests, If-None-Match %q", requests, ifNoneMatch)
+	}
+
+	maxAge = "max-age=60"
+	get()
+	get()
+	if got, want := requests, 3; got != want {
+		t.Errorf("A fresh response must not be requested again. Got: %v requests, Want: %v", got, want)
+	}
+
+	if err := client.Resources.UpdateInCollection("test:Counter", "1", &etagTestCounter{Count: 1}); err != nil {
+		t.Fatalf("UpdateInCollection returned error: %v", err)
+	}
+	get()
+	if requests != 5 || ifNoneMatch != "" {
+		t.Errorf("A write must remove the cached response. Got: %v requests, If-None-Match %q", requests, ifNoneMatch)
+	}
+
+	client.setToken("other", "", time.Now().Add(time.Hour).Unix()*1000)
+	get()
+	if requests != 6 || ifNoneMatch != "" {
+		t.Errorf("A response must not be used for another token. Got: %v requests, If-None-Match %q", requests, ifNoneMatch)
+	}
+
+	if err := client.IAM.OauthTokenUpgrade("assets"); err != nil {
+		t.Fatalf("OauthTokenUpgrade returned error: %v", err)
+	}
+	requests = 6
+	get()
+	if requests != 7 || ifNoneMatch != "" {
+		t.Errorf("A response must not be used once the token is upgraded. Got: %v requests, If-None-Match %q", requests, ifNoneMatch)
+	}
+}
+
+func TestCacheSharedOffline(t *testing.T) {
+	var requests int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(`{"count":1}`))
+	}))
+	client.Cache = NewLRUCache(10)
+
+	expiresAt := time.Now().Add(time.Hour).Unix() * 1000
+	for _, token := range []string{"a", "b", "a", "b"} {
+		client.setToken(token, "", expiresAt)
+		var counter etagTestCounter
+		if err := client.Resources.GetFromCollection("test:Counter", "1", &counter); err != nil {
+			t.Fatalf("GetFromCollection returned error: %v", err)
+		}
+	}
+	if got, want := requests, 2; got != want {
+		t.Errorf("Responses of different tokens must be cached side by side. Got: %v requests, Want: %v", got, want)
+	}
+}
+
+func TestCacheAssetsUpgradeTokenOffline(t *testing.T) {
+	var requests int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/asset/access":
+			// asked with No-Redirect, so the location is not followed
+			w.Header().Set("Location", "http://localhost/?grant_type=upgrade&assertion=assets")
+			w.WriteHeader(http.StatusNoContent)
+		case "/v1.0/oauth/token/upgrade":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			requests++
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Write([]byte(`{"count":1}`))
+		}
+	}))
+	client.Cache = NewLRUCache(10)
+	client.setToken("token", "", time.Now().Add(time.Hour).Unix()*1000)
+
+	get := func() {
+		t.Helper()
+		var counter etagTestCounter
+		if err := client.Resources.GetFromCollection("test:Counter", "1", &counter); err != nil {
+			t.Fatalf("GetFromCollection returned error: %v", err)
+		}
+	}
+	get()
+	get()
+	if err := client.Assets.UpgradeToken(); err != nil {
+		t.Fatalf("UpgradeToken returned error: %v", err)
+	}
+	get()
+	if got, want := requests, 2; got != want {
+		t.Errorf("A response must not be used once the token is upgraded by assets. Got: %v requests, Want: %v", got, want)
+	}
+}
+
+func TestCacheInvalidationOffline(t *testing.T) {
+	var (
+		requests int
+		items    []string
+	)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.Method == "POST":
+			items = append(items, "new")
+			w.Header().Set("Location", "http://localhost/v1.0/resource/test:Collection/new")
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "PUT":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Cache-Control", "max-age=60")
+			json.NewEncoder(w).Encode(items)
+		}
+	}))
+	client.Cache = NewLRUCache(10)
+
+	page := func(collectionName string) []string {
+		t.Helper()
+		result := []string{}
+		if err := client.Resources.SearchCollection(collectionName).Page(0, &result); err != nil {
+			t.Fatalf("Page returned error: %v", err)
+		}
+		return result
+	}
+
+	page("test:Collection")
+	page("test:CollectionOther")
+	if _, err := client.Resources.AddToCollection("test:Collection", map[string]string{"id": "new"}); err != nil {
+		t.Fatalf("AddToCollection returned error: %v", err)
+	}
+	if got, want := len(page("test:Collection")), 1; got != want {
+		t.Errorf("A write must remove the cached searches of its collection. Got: %v items, Want: %v", got, want)
+	}
+	requests = 0
+	page("test:CollectionOther")
+	if got, want := requests, 0; got != want {
+		t.Errorf("A write must not remove the cached responses of other collections. Got: %v requests, Want: %v", got, want)
+	}
+
+	if err := client.Resources.UpdateInCollection("test:Collection", "new", map[string]string{"id": "new"}); err != nil {
+		t.Fatalf("UpdateInCollection returned error: %v", err)
+	}
+	requests = 0
+	page("test:Collection")
+	if got, want := requests, 1; got != want {
+		t.Errorf("A write to a resource must remove the cached searches of its collection. Got: %v requests, Want: %v", got, want)
+	}
+}
+
+func TestCacheCollectionPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/v1.0/resource/test:Collection", "/v1.0/resource/test:Collection"},
+		{"/v1.0/resource/test:Collection/id", "/v1.0/resource/test:Collection"},
+		{"/v1.0/resource/test:Collection/id/test:Relation;r=test:Other/id2", "/v1.0/resource/test:Collection"},
+		{"/v1.0/user/1234", "/v1.0/user"},
+		{"/v1.0/user/me", "/v1.0/user"},
+		{"/v1.0", "/v1.0"},
+	}
+	for _, test := range tests {
+		if got, want := cacheCollectionPath(test.path), test.want; got != want {
+			t.Errorf("Error in collection path of %v. Got: %v, Want: %v", test.path, got, want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package corbel
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Collection is a typed handle of a resources collection. It binds the
+// collection name, so it's written once, and decodes the resources into T.
+//
+//	books := NewCollection[Book](client.Resources, "books:Book")
+//	book, err := books.Get("123")
+type Collection[T any] struct {
+	resources *ResourcesService
+	name      string
+}
+
+// NewCollection returns a handle of the collection name whose resources are
+// decoded into T
+func NewCollection[T any](resources *ResourcesService, name string) *Collection[T] {
+	return &Collection[T]{
+		resources: resources,
+		name:      name,
+	}
+}
+
+// Name returns the name of the collection
+func (c *Collection[T]) Name() string {
+	return c.name
+}
+
+// Get returns the resource of the collection by id
+func (c *Collection[T]) Get(id string) (T, error) {
+	return c.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get but uses ctx for the request.
+func (c *Collection[T]) GetContext(ctx context.Context, id string) (T, error) {
+	var resource T
+	err := c.resources.GetFromCollectionContext(ctx, c.name, id, &resource)
+	return resource, err
+}
+
+// Add adds the resource to the collection and returns its location
+func (c *Collection[T]) Add(resource T) (string, error) {
+	return c.AddContext(context.Background(), resource)
+}
+
+// AddContext is like Add but uses ctx for the request.
+func (c *Collection[T]) AddContext(ctx context.Context, resource T) (string, error) {
+	return c.resources.AddToCollectionContext(ctx, c.name, resource)
+}
+
+// Update replaces the resource of the collection by id
+func (c *Collection[T]) Update(id string, resource T) error {
+	return c.UpdateContext(context.Background(), id, resource)
+}
+
+// UpdateContext is like Update but uses ctx for the request.
+func (c *Collection[T]) UpdateContext(ctx context.Context, id string, resource T) error {
+	return c.resources.UpdateInCollectionContext(ctx, c.name, id, resource)
+}
+
+// Delete deletes the resource of the collection by id
+func (c *Collection[T]) Delete(id string) error {
+	return c.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete but uses ctx for the request.
+func (c *Collection[T]) DeleteContext(ctx context.Context, id string) error {
+	return c.resources.DeleteFromCollectionContext(ctx, c.name, id)
+}
+
+// Search returns the page pageNumber of the resources of the collection
+// matching spec
+func (c *Collection[T]) Search(spec QuerySpec, pageNumber int) ([]T, error) {
+	return c.SearchContext(context.Background(), spec, pageNumber)
+}
+
+// SearchContext is like Search but uses ctx for the request.
+func (c *Collection[T]) SearchContext(ctx context.Context, spec QuerySpec, pageNumber int) ([]T, error) {
+	var resources []T
+	if err := c.search(spec).PageContext(ctx, pageNumber, &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// All returns all the resources of the collection matching spec
+func (c *Collection[T]) All(spec QuerySpec) ([]T, error) {
+	return c.AllContext(context.Background(), spec)
+}
+
+// AllContext is like All but uses ctx for the requests.
+func (c *Collection[T]) AllContext(ctx context.Context, spec QuerySpec) ([]T, error) {
+	var resources []T
+	if err := c.search(spec).AllContext(ctx, &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// Iterate returns an iterator over all the resources of the collection
+// matching spec
+func (c *Collection[T]) Iterate(spec QuerySpec) *CollectionIterator[T] {
+	return c.IterateContext(context.Background(), spec)
+}
+
+// IterateContext is like Iterate but uses ctx for the requests.
+func (c *Collection[T]) IterateContext(ctx context.Context, spec QuerySpec) *CollectionIterator[T] {
+	return &CollectionIterator[T]{it: c.search(spec).IteratorContext(ctx)}
+}
+
+// search returns the search of the collection with spec applied
+func (c *Collection[T]) search(spec QuerySpec) *Search {
+	return spec.Bind(c.resources.SearchCollection(c.name))
+}
+
+// CollectionIterator walks the resources of a Collection decoded as T.
+//
+//	it := books.Iterate(NewQuerySpec())
+//	for it.Next() {
+//		book := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type CollectionIterator[T any] struct {
+	it    *Iterator
+	value T
+	err   error
+}
+
+// Next advances the iterator to the next resource and decodes it. It returns
+// false when there are no more resources or an error happened.
+func (ci *CollectionIterator[T]) Next() bool {
+	var zero T
+	ci.value = zero
+	if ci.err != nil || !ci.it.Next() {
+		return false
+	}
+	if ci.err = json.Unmarshal(ci.it.current, &ci.value); ci.err != nil {
+		return false
+	}
+	return true
+}
+
+// Value returns the current resource
+func (ci *CollectionIterator[T]) Value() T {
+	return ci.value
+}
+
+// Err returns the error that stopped the iteration, if any
+func (ci *CollectionIterator[T]) Err() error {
+	if ci.err != nil {
+		return ci.err
+	}
+	return ci.it.Err()
+}
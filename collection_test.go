@@ -0,0 +1,135 @@
+package corbel
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type collectionTestBook struct {
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title"`
+}
+
+func TestCollectionOffline(t *testing.T) {
+	books := map[string]collectionTestBook{}
+	next := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v1.0/resource/books:Book")
+		id := strings.TrimPrefix(path, "/")
+		switch {
+		case r.Method == "POST" && path == "":
+			var book collectionTestBook
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &book)
+			next++
+			book.ID = strconv.Itoa(next)
+			books[book.ID] = book
+			w.Header().Set("Location", "http://localhost/v1.0/resource/books:Book/"+book.ID)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "GET" && path == "":
+			list := []collectionTestBook{}
+			for i := 1; i <= next; i++ {
+				if book, ok := books[strconv.Itoa(i)]; ok {
+					list = append(list, book)
+				}
+			}
+			json.NewEncoder(w).Encode(list)
+		case r.Method == "GET":
+			book, ok := books[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(book)
+		case r.Method == "PUT":
+			var book collectionTestBook
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &book)
+			book.ID = id
+			books[id] = book
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "DELETE":
+			delete(books, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	collection := NewCollection[collectionTestBook](client.Resources, "books:Book")
+	if got, want := collection.Name(), "books:Book"; got != want {
+		t.Errorf("Error in collection name. Got: %v, Want: %v", got, want)
+	}
+
+	for _, title := range []string{"first", "second", "third"} {
+		if _, err := collection.Add(collectionTestBook{Title: title}); err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+	}
+
+	book, err := collection.Get("2")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got, want := book.Title, "second"; got != want {
+		t.Errorf("Error in book got. Got: %v, Want: %v", got, want)
+	}
+
+	book.Title = "updated"
+	if err := collection.Update("2", book); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if err := collection.Delete("1"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := collection.Get("1"); err == nil {
+		t.Error("Get of a deleted book must fail")
+	}
+
+	page, err := collection.Search(NewQuerySpec(), 0)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if got, want := len(page), 2; got != want {
+		t.Fatalf("Error in books searched. Got: %v, Want: %v", got, want)
+	}
+	if got, want := page[0].Title, "updated"; got != want {
+		t.Errorf("Error in book searched. Got: %v, Want: %v", got, want)
+	}
+
+	all, err := collection.All(NewQuerySpec())
+	if err != nil {
+		t.Fatalf("All returned error: %v", err)
+	}
+	if got, want := len(all), 2; got != want {
+		t.Errorf("Error in all books. Got: %v, Want: %v", got, want)
+	}
+
+	var titles []string
+	it := collection.Iterate(NewQuerySpec())
+	for it.Next() {
+		titles = append(titles, it.Value().Title)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterate returned error: %v", err)
+	}
+	if got, want := strings.Join(titles, ","), "updated,third"; got != want {
+		t.Errorf("Error in books iterated. Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestCollectionIteratorDecodeError(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"title":1}]`))
+	}))
+
+	it := NewCollection[collectionTestBook](client.Resources, "books:Book").Iterate(NewQuerySpec())
+	if it.Next() {
+		t.Error("Next must return false if the resource cannot be decoded")
+	}
+	if it.Err() == nil {
+		t.Error("Err must return the decoding error")
+	}
+}
@@ -0,0 +1,201 @@
+package corbel
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Profile is the configuration of a client for one environment, as stored in
+// the profiles file.
+type Profile struct {
+	Endpoints         map[string]string `json:"endpoints,omitempty" yaml:"endpoints,omitempty" toml:"endpoints,omitempty"`
+	ClientID          string            `json:"clientId,omitempty" yaml:"clientId,omitempty" toml:"clientId,omitempty"`
+	ClientName        string            `json:"clientName,omitempty" yaml:"clientName,omitempty" toml:"clientName,omitempty"`
+	ClientSecret      string            `json:"clientSecret,omitempty" yaml:"clientSecret,omitempty" toml:"clientSecret,omitempty"`
+	Scopes            []string          `json:"scopes,omitempty" yaml:"scopes,omitempty" toml:"scopes,omitempty"`
+	Domain            string            `json:"domain,omitempty" yaml:"domain,omitempty" toml:"domain,omitempty"`
+	SigningMethod     string            `json:"signingMethod,omitempty" yaml:"signingMethod,omitempty" toml:"signingMethod,omitempty"`
+	RSAPrivateKeyFile string            `json:"rsaPrivateKeyFile,omitempty" yaml:"rsaPrivateKeyFile,omitempty" toml:"rsaPrivateKeyFile,omitempty"`
+	// TokenTTL is the time in seconds that the tokens must be valid.
+	TokenTTL uint64 `json:"tokenTTL,omitempty" yaml:"tokenTTL,omitempty" toml:"tokenTTL,omitempty"`
+	LogLevel string `json:"logLevel,omitempty" yaml:"logLevel,omitempty" toml:"logLevel,omitempty"`
+}
+
+// DefaultProfile is the name of the profile used when none is selected.
+const DefaultProfile = "default"
+
+// DefaultConfigPath returns the default profiles file, ~/.corbel/config.
+func DefaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".corbel", "config")
+}
+
+// LoadProfile reads the profile name from the profiles file in path and
+// overrides its values with the CORBEL_* environment variables.
+//
+// The format of the file is chosen by its extension: .json, .yaml, .yml or
+// TOML for any other one. Every profile is a top level section of the file.
+// If path is empty CORBEL_CONFIG or DefaultConfigPath is used, and it's not
+// an error if that file does not exist unless a profile other than
+// DefaultProfile is selected. If name is empty CORBEL_PROFILE or
+// DefaultProfile is used. Empty profiles are allowed.
+//
+// Allowed environment variables are CORBEL_CLIENT_ID, CORBEL_CLIENT_NAME,
+// CORBEL_CLIENT_SECRET, CORBEL_SCOPES (delimited by spaces), CORBEL_DOMAIN,
+// CORBEL_SIGNING_METHOD, CORBEL_RSA_PRIVATE_KEY_FILE, CORBEL_TOKEN_TTL,
+// CORBEL_LOG_LEVEL and CORBEL_ENDPOINT_<NAME> for every endpoint.
+func LoadProfile(path, name string) (*Profile, error) {
+	required := path != ""
+	if path == "" {
+		path = os.Getenv("CORBEL_CONFIG")
+		required = path != ""
+	}
+	if path == "" {
+		path = DefaultConfigPath()
+	}
+	if name == "" {
+		name = os.Getenv("CORBEL_PROFILE")
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	profile := &Profile{}
+	profiles, err := readProfiles(path)
+	switch {
+	case err == nil:
+		var ok bool
+		if profile, ok = profiles[name]; !ok {
+			return nil, errInvalidEnvironment
+		}
+		if profile == nil {
+			profile = &Profile{}
+		}
+	case os.IsNotExist(err) && !required:
+		// only the default profile can be taken from the environment alone
+		if name != DefaultProfile {
+			return nil, errInvalidEnvironment
+		}
+	default:
+		return nil, err
+	}
+
+	if err = profile.applyEnv(); err != nil {
+		return nil, err
+	}
+	for endpoint := range profile.Endpoints {
+		if !stringInSlice(allowedEndpoints, endpoint) {
+			return nil, errInvalidEndpoint
+		}
+	}
+	return profile, nil
+}
+
+// NewFromProfile returns a new Corbel API client configured by the profile
+// loaded with LoadProfile. The given options are applied after the profile.
+func NewFromProfile(path, name string, options ...Option) (*Client, error) {
+	profile, err := LoadProfile(path, name)
+	if err != nil {
+		return nil, err
+	}
+	return New(append(profile.Options(), options...)...)
+}
+
+// Options returns the options to create a client with the profile values.
+func (p *Profile) Options() []Option {
+	options := []Option{
+		WithEndpoints(p.Endpoints),
+		WithCredentials(p.ClientID, p.ClientSecret),
+		WithClientName(p.ClientName),
+		WithScopes(p.Scopes...),
+		WithDomain(p.Domain),
+	}
+	if p.SigningMethod != "" {
+		options = append(options, WithSigningMethod(p.SigningMethod))
+	}
+	if p.RSAPrivateKeyFile != "" {
+		options = append(options, WithRSAPrivateKeyFile(p.RSAPrivateKeyFile))
+	}
+	if p.TokenTTL != 0 {
+		options = append(options, WithTokenTTL(time.Duration(p.TokenTTL)*time.Second))
+	}
+	if p.LogLevel != "" {
+		options = append(options, WithLogLevel(p.LogLevel))
+	}
+	return options
+}
+
+// readProfiles decodes the profiles file in path
+func readProfiles(path string) (map[string]*Profile, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]*Profile)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &profiles)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &profiles)
+	default:
+		_, err = toml.Decode(string(content), &profiles)
+	}
+	if err != nil {
+		return nil, errInvalidConfig
+	}
+	return profiles, nil
+}
+
+// applyEnv overrides the profile values with the CORBEL_* environment
+// variables
+func (p *Profile) applyEnv() error {
+	values := map[string]*string{
+		"CORBEL_CLIENT_ID":            &p.ClientID,
+		"CORBEL_CLIENT_NAME":          &p.ClientName,
+		"CORBEL_CLIENT_SECRET":        &p.ClientSecret,
+		"CORBEL_DOMAIN":               &p.Domain,
+		"CORBEL_SIGNING_METHOD":       &p.SigningMethod,
+		"CORBEL_RSA_PRIVATE_KEY_FILE": &p.RSAPrivateKeyFile,
+		"CORBEL_LOG_LEVEL":            &p.LogLevel,
+	}
+	for key, value := range values {
+		if env, ok := os.LookupEnv(key); ok {
+			*value = env
+		}
+	}
+
+	if env, ok := os.LookupEnv("CORBEL_SCOPES"); ok {
+		p.Scopes = []string{env}
+	}
+	if env, ok := os.LookupEnv("CORBEL_TOKEN_TTL"); ok {
+		ttl, err := strconv.ParseUint(env, 10, 64)
+		if err != nil {
+			return errInvalidTokenExpirationTime
+		}
+		p.TokenTTL = ttl
+	}
+
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, "CORBEL_ENDPOINT_") {
+			continue
+		}
+		pair := strings.SplitN(strings.TrimPrefix(env, "CORBEL_ENDPOINT_"), "=", 2)
+		if p.Endpoints == nil {
+			p.Endpoints = make(map[string]string)
+		}
+		p.Endpoints[strings.ToLower(pair[0])] = pair[1]
+	}
+	return nil
+}
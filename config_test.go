@@ -0,0 +1,176 @@
+package corbel
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const testConfigTOML = `
+[staging]
+clientId = "stagingID"
+clientSecret = "stagingSecret"
+scopes = ["scope1", "scope2"]
+domain = "staging-domain"
+tokenTTL = 300
+
+[staging.endpoints]
+iam = "https://iam-staging.bqws.io"
+resources = "https://resources-staging.bqws.io"
+
+[prod]
+clientId = "prodID"
+clientSecret = "prodSecret"
+`
+
+const testConfigJSON = `{
+  "staging": {
+    "clientId": "stagingID",
+    "clientSecret": "stagingSecret",
+    "scopes": ["scope1", "scope2"],
+    "domain": "staging-domain",
+    "tokenTTL": 300,
+    "endpoints": {"iam": "https://iam-staging.bqws.io", "resources": "https://resources-staging.bqws.io"}
+  }
+}`
+
+const testConfigYAML = `
+staging:
+  clientId: stagingID
+  clientSecret: stagingSecret
+  scopes: [scope1, scope2]
+  domain: staging-domain
+  tokenTTL: 300
+  endpoints:
+    iam: https://iam-staging.bqws.io
+    resources: https://resources-staging.bqws.io
+`
+
+func writeTestConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigLoadProfile(t *testing.T) {
+	files := map[string]string{
+		"config":      testConfigTOML,
+		"config.json": testConfigJSON,
+		"config.yaml": testConfigYAML,
+	}
+	for name, content := range files {
+		path := writeTestConfig(t, name, content)
+
+		client, err := NewFromProfile(path, "staging")
+		if err != nil {
+			t.Fatalf("NewFromProfile must not fail with %s. Got: %v", name, err)
+		}
+		if got, want := client.ClientID, "stagingID"; got != want {
+			t.Errorf("%s ClientID is %v, but want %v", name, got, want)
+		}
+		if got, want := client.ClientSecret, "stagingSecret"; got != want {
+			t.Errorf("%s ClientSecret is %v, but want %v", name, got, want)
+		}
+		if got, want := client.ClientScopes, "scope1 scope2"; got != want {
+			t.Errorf("%s ClientScopes is %v, but want %v", name, got, want)
+		}
+		if got, want := client.ClientDomain, "staging-domain"; got != want {
+			t.Errorf("%s ClientDomain is %v, but want %v", name, got, want)
+		}
+		if got, want := client.TokenExpirationTime, uint64(300000); got != want {
+			t.Errorf("%s TokenExpirationTime is %v, but want %v", name, got, want)
+		}
+		if got, want := client.Endpoints["resources"], "https://resources-staging.bqws.io"; got != want {
+			t.Errorf("%s resources endpoint is %v, but want %v", name, got, want)
+		}
+	}
+}
+
+func TestConfigEnvironment(t *testing.T) {
+	path := writeTestConfig(t, "config", testConfigTOML)
+	t.Setenv("CORBEL_CONFIG", path)
+	t.Setenv("CORBEL_PROFILE", "prod")
+	t.Setenv("CORBEL_CLIENT_SECRET", "envSecret")
+	t.Setenv("CORBEL_SCOPES", "scope1 scope3")
+	t.Setenv("CORBEL_TOKEN_TTL", "600")
+	t.Setenv("CORBEL_ENDPOINT_IAM", "https://iam-env.bqws.io")
+
+	profile, err := LoadProfile("", "")
+	if err != nil {
+		t.Fatalf("LoadProfile must not fail. Got: %v", err)
+	}
+	if got, want := profile.ClientID, "prodID"; got != want {
+		t.Errorf("ClientID is %v, but want %v", got, want)
+	}
+	if got, want := profile.ClientSecret, "envSecret"; got != want {
+		t.Errorf("ClientSecret is %v, but want %v", got, want)
+	}
+	if got, want := profile.TokenTTL, uint64(600); got != want {
+		t.Errorf("TokenTTL is %v, but want %v", got, want)
+	}
+	if got, want := profile.Endpoints["iam"], "https://iam-env.bqws.io"; got != want {
+		t.Errorf("iam endpoint is %v, but want %v", got, want)
+	}
+
+	client, err := New(profile.Options()...)
+	if err != nil {
+		t.Fatalf("New must not fail. Got: %v", err)
+	}
+	if got, want := client.ClientScopes, "scope1 scope3"; got != want {
+		t.Errorf("ClientScopes is %v, but want %v", got, want)
+	}
+
+	t.Setenv("CORBEL_ENDPOINT_UNKNOWN", "https://unknown.bqws.io")
+	if _, err = LoadProfile("", ""); err != errInvalidEndpoint {
+		t.Errorf("LoadProfile must fail with an unknown endpoint. Got: %v", err)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	path := writeTestConfig(t, "config", testConfigTOML)
+	if _, err := LoadProfile(path, "missing"); err != errInvalidEnvironment {
+		t.Errorf("LoadProfile must fail with an unknown profile. Got: %v", err)
+	}
+
+	for name, content := range map[string]string{
+		"config":      "[staging]\n",
+		"config.json": `{"staging": null}`,
+		"config.yaml": "staging:\n",
+	} {
+		path = writeTestConfig(t, name, content)
+		if _, err := LoadProfile(path, "staging"); err != nil {
+			t.Errorf("LoadProfile must not fail with an empty profile in %s. Got: %v", name, err)
+		}
+	}
+
+	path = writeTestConfig(t, "config.json", "{")
+	if _, err := LoadProfile(path, "staging"); err != errInvalidConfig {
+		t.Errorf("LoadProfile must fail with an invalid file. Got: %v", err)
+	}
+
+	if _, err := LoadProfile(filepath.Join(t.TempDir(), "missing"), "staging"); err == nil {
+		t.Errorf("LoadProfile must fail if the given file does not exist")
+	}
+
+	// the default file is optional
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CORBEL_CLIENT_ID", "envID")
+	profile, err := LoadProfile("", "")
+	if err != nil {
+		t.Fatalf("LoadProfile must not fail without the default file. Got: %v", err)
+	}
+	if got, want := profile.ClientID, "envID"; got != want {
+		t.Errorf("ClientID is %v, but want %v", got, want)
+	}
+
+	// but other profiles can't be taken from it
+	if _, err := LoadProfile("", "prod"); err != errInvalidEnvironment {
+		t.Errorf("LoadProfile must fail with a profile and without the default file. Got: %v", err)
+	}
+	t.Setenv("CORBEL_PROFILE", "prod")
+	if _, err := LoadProfile("", ""); err != errInvalidEnvironment {
+		t.Errorf("LoadProfile must fail with CORBEL_PROFILE and without the default file. Got: %v", err)
+	}
+}
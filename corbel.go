@@ -1,8 +1,10 @@
 package corbel
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -19,7 +21,7 @@ var (
 func init() {
 	userAgent = fmt.Sprintf("corbel-go/%s", Version)
 	allowedEndpoints = []string{"iam", "oauth", "assets", "resources"}
-	allowedJTWSigningMethods = []string{"HS256", "RSA"}
+	allowedJTWSigningMethods = []string{"HS256", "RS256", "RS384", "RS512", "RSA"}
 }
 
 // Client is the struct that manages communication with the Corbel APIs.
@@ -38,6 +40,7 @@ type Client struct {
 	ClientID string
 
 	// ClientSecret is the application secret hash that match with clientID.
+	// For clients using RSA signing methods it is the PEM encoded private key.
 	ClientSecret string
 
 	// ClientScopes are those scopes the client will ask for to the platform when building the client connection
@@ -50,17 +53,36 @@ type Client struct {
 
 	// ClientJWTSigningMethod defines the signing method configured for the client.
 	// Must match with the one configured on the platform since it will understand only that one.
-	// Only allowed signing methods at the moment are: HS256 and RSA
+	// Only allowed signing methods at the moment are: HS256, RS256, RS384 and RS512.
+	// RSA is accepted as an alias of RS256.
 	ClientJWTSigningMethod string
 
+	// signingKey is the key used to sign the client assertions.
+	signingKey interface{}
+
 	// TokenExpirationTime define the amount of time in seconds that a token must be valid.
 	// It must be lower than 3600 seconds, since is the imposed requisite from the platform.
 	TokenExpirationTime uint64
 
+	// TokenRefreshSkew is the clock skew tolerance applied to the token
+	// expiration. Tokens that expire within this duration are refreshed before
+	// being sent.
+	TokenRefreshSkew time.Duration
+
+	// RetryPolicy defines how requests failed because of connection errors or
+	// transient server errors are retried. If nil requests are not retried.
+	RetryPolicy *RetryPolicy
+
+	// Cache stores the responses of GET requests to answer them again without
+	// downloading them while they are fresh, or to revalidate them with
+	// If-None-Match and If-Modified-Since. If nil responses are not cached.
+	Cache CacheStore
+
 	// UserAgent defines the UserAgent to send in the Headers for every request to the platform.
 	UserAgent string
 
 	// Token is the actual token to send as Authentication Bearer
+	// Use Token() to read it when the client is shared between goroutines.
 	CurrentToken string
 
 	// CurrentTokenExpiresAt is the unix time where the token will expire
@@ -69,13 +91,32 @@ type Client struct {
 	// CurrentRefreshToken is the current refresh token received from the IAM service
 	CurrentRefreshToken string
 
+	// tokenMu protects the Current* token fields and tokenRefresh, since a
+	// client can be shared between goroutines.
+	tokenMu sync.Mutex
+
+	// tokenRefresh is the token refresh in flight, if any.
+	tokenRefresh *tokenRefresh
+
+	// lastGrant runs again the last grant used to get the current token.
+	lastGrant func(context.Context) error
+
+	// tokenUpgrades counts the upgrades of the current token, which add
+	// scopes to it without changing it.
+	tokenUpgrades int
+
+	// renewalMu protects the channels of the background token renewal.
+	renewalMu   sync.Mutex
+	renewalStop chan struct{}
+	renewalDone chan struct{}
+
 	// IAM endpoint struct
 	IAM       *IAMService
 	Resources *ResourcesService
 	Assets    *AssetsService
 
 	// Logger
-	logger *logrus.Logger
+	logger *logger
 
 	// LogLevel for the logger.
 	LogLevel string
@@ -86,23 +127,150 @@ func (c *Client) URLFor(endpoint, uri string) string {
 	return fmt.Sprintf("%s%s", c.Endpoints[endpoint], uri)
 }
 
+// tokenRefresh is a token refresh in flight shared by all the goroutines that
+// found the token expired or revoked at the same time.
+type tokenRefresh struct {
+	done chan struct{}
+	err  error
+}
+
 // Token returns the token to use as bearer. If the token has already expired
 // it refresh it.
 func (c *Client) Token() string {
-	// if CurrentToken == "" then return it as is
-	if c.CurrentToken == "" {
-		return c.CurrentToken
+	token, err := c.TokenContext(context.Background())
+	if err != nil {
+		c.logger.Warnf("failed to refresh token: %v", err)
 	}
+	return token
+}
+
+// TokenContext returns the token to use as bearer. If the token has already
+// expired, or expires within TokenRefreshSkew, it refresh it using ctx for the
+// request to IAM. Concurrent calls share a single refresh request.
+func (c *Client) TokenContext(ctx context.Context) (string, error) {
+	return c.token(ctx, c.TokenRefreshSkew)
+}
+
+// token returns the current token refreshing it if it expires within margin
+func (c *Client) token(ctx context.Context, margin time.Duration) (string, error) {
+	c.tokenMu.Lock()
+	token := c.CurrentToken
+	// if CurrentToken == "" then return it as is
 	// if we have CurrentToken check if already expired
-	if c.CurrentTokenExpiresAt <= time.Now().Unix()*1000 {
-		c.logger.Debug("refreshing token")
-		if c.CurrentRefreshToken != "" {
-			_ = c.IAM.RefreshToken()
-		} else {
-			_ = c.IAM.OauthToken()
+	if token == "" || c.CurrentTokenExpiresAt > time.Now().Add(margin).Unix()*1000 {
+		c.tokenMu.Unlock()
+		return token, nil
+	}
+	c.tokenMu.Unlock()
+
+	if err := c.replaceToken(ctx, token, c.renewExpiredToken); err != nil {
+		return "", err
+	}
+
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.CurrentToken, nil
+}
+
+// renewExpiredToken gets a new token once the current one has expired
+func (c *Client) renewExpiredToken(ctx context.Context) error {
+	c.logger.Debug("refreshing token")
+	c.tokenMu.Lock()
+	refreshToken, grant := c.CurrentRefreshToken, c.lastGrant
+	c.tokenMu.Unlock()
+
+	switch {
+	case refreshToken != "":
+		return c.IAM.RefreshTokenContext(ctx)
+	case grant != nil:
+		return grant(ctx)
+	default:
+		return c.IAM.OauthTokenContext(ctx)
+	}
+}
+
+// renewRevokedToken gets a new token once the platform rejected the current
+// one by running again the last grant used to get it.
+func (c *Client) renewRevokedToken(ctx context.Context) error {
+	c.logger.Debug("token rejected, authenticating again")
+	c.tokenMu.Lock()
+	refreshToken, grant := c.CurrentRefreshToken, c.lastGrant
+	c.tokenMu.Unlock()
+
+	switch {
+	case grant != nil:
+		return grant(ctx)
+	case refreshToken != "":
+		return c.IAM.RefreshTokenContext(ctx)
+	default:
+		return errNoGrant
+	}
+}
+
+// replaceToken replaces the stale token using renew. Goroutines replacing the
+// same token at the same time share a single call to renew, and nothing is
+// done if the token was already replaced.
+func (c *Client) replaceToken(ctx context.Context, stale string, renew func(context.Context) error) error {
+	for {
+		c.tokenMu.Lock()
+		if c.CurrentToken != stale {
+			c.tokenMu.Unlock()
+			return nil
 		}
+
+		refresh := c.tokenRefresh
+		if refresh == nil {
+			refresh = &tokenRefresh{done: make(chan struct{})}
+			c.tokenRefresh = refresh
+			c.tokenMu.Unlock()
+
+			refresh.err = renew(ctx)
+
+			c.tokenMu.Lock()
+			c.tokenRefresh = nil
+			c.tokenMu.Unlock()
+			close(refresh.done)
+			return refresh.err
+		}
+		c.tokenMu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-refresh.done:
+		}
+		// the refresh was canceled by the context of another caller, so try
+		// again with ours
+		if refresh.err != nil && isContextError(refresh.err) && ctx.Err() == nil {
+			continue
+		}
+		return refresh.err
 	}
-	return c.CurrentToken
+}
+
+// setGrant stores the grant used to get the current token so it can be run
+// again if the platform rejects the token.
+func (c *Client) setGrant(grant func(context.Context) error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.lastGrant = grant
+}
+
+// tokenUpgraded records that the current token got new scopes, so the
+// responses cached with the previous ones are not used anymore
+func (c *Client) tokenUpgraded() {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.tokenUpgrades++
+}
+
+// setToken stores the tokens received from IAM
+func (c *Client) setToken(accessToken, refreshToken string, expiresAt int64) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.CurrentToken = accessToken
+	c.CurrentTokenExpiresAt = expiresAt
+	c.CurrentRefreshToken = refreshToken
 }
 
 // DefaultClient return a client with most of its values set to the default ones
@@ -114,39 +282,92 @@ func DefaultClient(endpoints map[string]string, clientID, clientName, clientSecr
 // If a nil httpClient is provided, it will return a http.DefaultClient.
 // If a empty environment is provided, it will use production as environment.
 func NewClient(httpClient *http.Client, endpoints map[string]string, clientID, clientName, clientSecret, clientScopes, clientDomain, clientJWTSigningMethod string, tokenExpirationTime uint64, logLevel string) (*Client, error) {
-	if httpClient == nil {
-		httpClient = http.DefaultClient
+	// incorrect Token Expiration Time? checked here too since it could
+	// overflow as time.Duration
+	if tokenExpirationTime > 3600 {
+		return nil, errInvalidTokenExpirationTime
 	}
 
-	if len(endpoints) == 0 {
-		endpoints = map[string]string{"iam": "https://iam.bqws.io", "resources": "https://resources.bqws.io"}
+	return New(
+		WithHTTPClient(httpClient),
+		WithEndpoints(endpoints),
+		WithCredentials(clientID, clientSecret),
+		WithClientName(clientName),
+		WithScopes(clientScopes),
+		WithDomain(clientDomain),
+		WithSigningMethod(clientJWTSigningMethod),
+		WithTokenTTL(time.Duration(tokenExpirationTime)*time.Second),
+		WithLogLevel(logLevel),
+	)
+}
+
+// New returns a new Corbel API client configured by the given options.
+// At least the client credentials must be provided using WithCredentials.
+// By default it uses http.DefaultClient, the production endpoints, HS256 as
+// signing method, tokens valid for 3600 seconds and "info" as log level.
+func New(options ...Option) (*Client, error) {
+	opts := &clientOptions{
+		httpClient:    http.DefaultClient,
+		endpoints:     map[string]string{"iam": "https://iam.bqws.io", "resources": "https://resources.bqws.io"},
+		signingMethod: "HS256",
+		tokenTTL:      3600 * time.Second,
+	}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// an RSA private key replaces the client secret, whatever the order of
+	// the options
+	if opts.rsaPrivateKey != "" {
+		if !opts.signingMethodSet {
+			opts.signingMethod = "RS256"
+		}
+		if !isRSASigningMethod(opts.signingMethod) {
+			return nil, errRSAKeyWithHMAC
+		}
+		opts.clientSecret = opts.rsaPrivateKey
 	}
 
 	// allowedJTWSigningMethods?
-	if stringInSlice(allowedJTWSigningMethods, clientJWTSigningMethod) == false {
+	if stringInSlice(allowedJTWSigningMethods, opts.signingMethod) == false {
 		return nil, errInvalidJWTSigningMethod
 	}
 
 	// incorrect Token Expiration Time?
+	tokenExpirationTime := uint64(opts.tokenTTL / time.Second)
 	if tokenExpirationTime > 3600 || tokenExpirationTime == 0 {
 		return nil, errInvalidTokenExpirationTime
 	}
 
 	// required parameters?
-	if clientID == "" || clientSecret == "" {
+	if opts.clientID == "" || opts.clientSecret == "" {
 		return nil, errMissingClientParams
 	}
 
+	if opts.signingMethod == "RSA" {
+		opts.signingMethod = "RS256"
+	}
+	key, err := signingKey(opts.signingMethod, opts.clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
 	thisClient := &Client{
-		httpClient:             httpClient,
-		Endpoints:              endpoints,
-		ClientName:             clientName,
-		ClientID:               clientID,
-		ClientSecret:           clientSecret,
-		ClientDomain:           clientDomain,
-		ClientScopes:           clientScopes,
-		ClientJWTSigningMethod: clientJWTSigningMethod,
+		httpClient:             opts.httpClient,
+		Endpoints:              opts.endpoints,
+		ClientName:             opts.clientName,
+		ClientID:               opts.clientID,
+		ClientSecret:           opts.clientSecret,
+		ClientDomain:           opts.domain,
+		ClientScopes:           opts.scopes,
+		ClientJWTSigningMethod: opts.signingMethod,
+		signingKey:             key,
 		TokenExpirationTime:    tokenExpirationTime * 1000,
+		TokenRefreshSkew:       opts.tokenRefreshSkew,
+		RetryPolicy:            opts.retryPolicy,
+		Cache:                  opts.cache,
 		UserAgent:              userAgent,
 	}
 
@@ -154,13 +375,24 @@ func NewClient(httpClient *http.Client, endpoints map[string]string, clientID, c
 	thisClient.Resources = &ResourcesService{client: thisClient}
 	thisClient.Assets = &AssetsService{client: thisClient}
 
-	level, err := logrus.ParseLevel(logLevel)
+	// custom loggers get every message unless a level is given
+	if !opts.logLevelSet {
+		opts.logLevel = LevelInfo.String()
+		if opts.logger != nil {
+			opts.logLevel = LevelDebug.String()
+		}
+	}
+	level, err := parseLevel(opts.logLevel)
 	if err != nil {
-		return nil, errInvalidLogLevel
+		return nil, err
+	}
+	if opts.logger == nil {
+		logrusLogger := logrus.New()
+		logrusLogger.Level = logrus.DebugLevel
+		opts.logger = NewLogrusLogger(logrusLogger)
 	}
-	thisClient.logger = logrus.New()
-	thisClient.logger.Level = level
-	thisClient.LogLevel = logLevel
+	thisClient.logger = &logger{Logger: opts.logger, level: level}
+	thisClient.LogLevel = opts.logLevel
 
 	return thisClient, nil
 }
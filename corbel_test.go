@@ -1,11 +1,36 @@
 package corbel
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
+// newTestClient returns a client whose endpoints all point to a local test
+// server served by handler. The server is closed when the test ends.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	endpoints := map[string]string{
+		"iam":       server.URL,
+		"oauth":     server.URL,
+		"assets":    server.URL,
+		"resources": server.URL,
+	}
+	client, err := NewClient(nil, endpoints, "someID", "", "someSecret", "", "", "HS256", 3000, "info")
+	if err != nil {
+		t.Fatalf("NewClient must not fail. Got: %v", err)
+	}
+	return client
+}
+
 func TestClientNewClient(t *testing.T) {
 	var (
 		client *Client
@@ -75,3 +100,76 @@ func TestClientURLFor(t *testing.T) {
 		t.Errorf("urlFor url is %v, but want %v", got, want)
 	}
 }
+
+func TestClientTokenContext(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("no request must reach the server with a canceled context. Got: %s %s", r.Method, r.URL)
+	}))
+
+	client.CurrentToken = "expired"
+	client.CurrentTokenExpiresAt = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.TokenContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("TokenContext must fail with a canceled context. Got: %v", err)
+	}
+
+	if _, err := client.NewRequestContext(ctx, "GET", "resources", "/v1.0/resource/test:Collection", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("NewRequestContext must fail if the token cannot be refreshed. Got: %v", err)
+	}
+}
+
+func TestClientTokenConcurrentRefresh(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		expiresAt := time.Now().Add(time.Hour).Unix() * 1000
+		fmt.Fprintf(w, `{"accessToken":"newToken","expiresAt":%d,"refreshToken":"newRefresh"}`, expiresAt)
+	}))
+
+	client.CurrentToken = "expired"
+	client.CurrentRefreshToken = "refresh"
+	client.CurrentTokenExpiresAt = 0
+
+	var wg sync.WaitGroup
+	for n := 0; n < 50; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := client.TokenContext(context.Background())
+			if err != nil {
+				t.Errorf("TokenContext must not fail. Got: %v", err)
+			}
+			if got, want := token, "newToken"; got != want {
+				t.Errorf("TokenContext token is %v, but want %v", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := atomic.LoadInt32(&calls), int32(1); got != want {
+		t.Errorf("Concurrent refreshes must be collapsed. Got %v requests, want %v", got, want)
+	}
+	if got, want := client.CurrentRefreshToken, "newRefresh"; got != want {
+		t.Errorf("CurrentRefreshToken is %v, but want %v", got, want)
+	}
+}
+
+func TestClientTokenRefreshError(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	client.CurrentToken = "expired"
+	client.CurrentTokenExpiresAt = 0
+
+	if _, err := client.TokenContext(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("TokenContext must return the refresh error. Got: %v", err)
+	}
+	if _, err := client.NewRequest("GET", "resources", "/v1.0/resource/test:Collection", nil); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("NewRequest must return the refresh error. Got: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package corbel
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Created is the location of an entity created in the platform, parsed from
+// the Location header returned when adding it.
+type Created struct {
+	// ID is the id of the created entity
+	ID string
+	// Collection is the collection, or kind of entity, the entity was created
+	// in. For relations it's the related collection.
+	Collection string
+	// URL is the full location of the created entity
+	URL string
+}
+
+// parseCreated returns the Created parsed from location. The id is the last
+// segment of the path and the collection the previous one.
+func parseCreated(location string) (*Created, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Path == "" {
+		return nil, errInvalidLocation
+	}
+
+	segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	if len(segments) < 2 {
+		return nil, errInvalidLocation
+	}
+	id, err := url.PathUnescape(segments[len(segments)-1])
+	if err != nil || id == "" {
+		return nil, errInvalidLocation
+	}
+	collection, err := url.PathUnescape(segments[len(segments)-2])
+	if err != nil {
+		return nil, errInvalidLocation
+	}
+	// relations are located at .../relationName;r=relatedCollection/relatedID
+	if i := strings.Index(collection, ";r="); i >= 0 {
+		collection = collection[i+len(";r="):]
+	}
+
+	return &Created{
+		ID:         id,
+		Collection: collection,
+		URL:        location,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package corbel
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCreatedParse(t *testing.T) {
+	tests := []struct {
+		location   string
+		id         string
+		collection string
+	}{
+		{"https://resources.bqws.io/v1.0/resource/books:Book/123", "123", "books:Book"},
+		{"https://iam.bqws.io/v1.0/user/abc/", "abc", "user"},
+		{"https://iam.bqws.io/v1.0/domain/silkroad-qa/client/f00", "f00", "client"},
+		{"/v1.0/resource/test:Group/1/test:Users;r=test:User/a%20b", "a b", "test:User"},
+	}
+
+	for _, test := range tests {
+		created, err := parseCreated(test.location)
+		if err != nil {
+			t.Errorf("parseCreated(%q) returned error: %v", test.location, err)
+			continue
+		}
+		if got, want := created.ID, test.id; got != want {
+			t.Errorf("Error in created ID of %q. Got: %v, Want: %v", test.location, got, want)
+		}
+		if got, want := created.Collection, test.collection; got != want {
+			t.Errorf("Error in created Collection of %q. Got: %v, Want: %v", test.location, got, want)
+		}
+		if got, want := created.URL, test.location; got != want {
+			t.Errorf("Error in created URL. Got: %v, Want: %v", got, want)
+		}
+	}
+
+	for _, location := range []string{"", "https://iam.bqws.io", "https://iam.bqws.io/user", "%zz"} {
+		if _, err := parseCreated(location); err != errInvalidLocation {
+			t.Errorf("parseCreated(%q) must fail. Got: %v", location, err)
+		}
+	}
+}
+
+func TestCreatedOffline(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1.0/resource/books:Book":
+			w.Header().Set("Location", "http://"+r.Host+"/v1.0/resource/books:Book/42")
+			w.WriteHeader(http.StatusCreated)
+		case "GET /v1.0/resource/books:Book/42":
+			w.Write([]byte(`{"id":"42","title":"Dune"}`))
+		case "POST /v1.0/user":
+			w.Header().Set("Location", "http://"+r.Host+"/v1.0/user/u1")
+			w.WriteHeader(http.StatusCreated)
+		case "GET /v1.0/user/u1":
+			w.Write([]byte(`{"id":"u1","username":"corbel-go"}`))
+		case "PUT /v1.0/resource/test:Group/1/test:Users;r=test:User/7":
+			w.Header().Set("Location", "http://"+r.Host+"/v1.0/resource/test:Group/1/test:Users;r=test:User/7")
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	var book collectionTestBook
+	created, err := client.Resources.AddToCollectionCreated("books:Book", collectionTestBook{Title: "Dune"}, &book)
+	if err != nil {
+		t.Fatalf("AddToCollectionCreated returned error: %v", err)
+	}
+	if got, want := created.ID, "42"; got != want {
+		t.Errorf("Error in created ID. Got: %v, Want: %v", got, want)
+	}
+	if got, want := book.Title, "Dune"; got != want {
+		t.Errorf("Error in fetched resource. Got: %v, Want: %v", got, want)
+	}
+
+	var user IAMUser
+	created, err = client.IAM.UserAddCreated(&IAMUser{Username: "corbel-go"}, &user)
+	if err != nil {
+		t.Fatalf("UserAddCreated returned error: %v", err)
+	}
+	if got, want := created.Collection, "user"; got != want {
+		t.Errorf("Error in created Collection. Got: %v, Want: %v", got, want)
+	}
+	if got, want := user.ID, "u1"; got != want {
+		t.Errorf("Error in fetched user. Got: %v, Want: %v", got, want)
+	}
+
+	requests = nil
+	created, err = client.Resources.AddRelationCreated("test:Group", "1", "test:Users", "test:User", "7", nil, nil)
+	if err != nil {
+		t.Fatalf("AddRelationCreated returned error: %v", err)
+	}
+	if got, want := created.ID+" "+created.Collection, "7 test:User"; got != want {
+		t.Errorf("Error in created relation. Got: %v, Want: %v", got, want)
+	}
+	if got, want := len(requests), 1; got != want {
+		t.Errorf("Nothing must be fetched when fetched is nil. Got: %v requests, Want: %v", got, want)
+	}
+}
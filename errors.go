@@ -1,21 +1,118 @@
 package corbel
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
 
 var (
 	errMissingClientParams        = errors.New("Client: Missing parameters for the Client. client ID or Secret cannot be empty.")
 	errInvalidEnvironment         = errors.New("Client: Environment is not valid.")
+	errInvalidEndpoint            = errors.New("Client: Endpoint is not valid.")
+	errInvalidConfig              = errors.New("Client: Invalid configuration file.")
 	errInvalidJWTSigningMethod    = errors.New("Client: Invalid JWT Signing Method.")
+	errInvalidRSAPrivateKey       = errors.New("Client: Invalid RSA private key. It must be PEM encoded.")
+	errRSAKeyWithHMAC             = errors.New("Client: An RSA private key can't be used with an HMAC signing method.")
 	errIdentifierEmpty            = errors.New("Client: Identifier can't be empty.")
 	errUserNotFound               = errors.New("Client: User not found.")
 	errInvalidTokenExpirationTime = errors.New("Client: Invalid TokenExpirationTime. Allowed range: 1-3600 seconds.")
-	errHTTPNotAuthorized          = errors.New("HTTP: 401 Not authorized")
-	errHTTPConflict               = errors.New("HTTP: 409 Conflict")
-	errHTTPInvalidEntity          = errors.New("HTTP: 422 Invalid Entity")
+	errHTTPNotAuthorized          = ErrUnauthorized
+	errHTTPConflict               = ErrConflict
+	errHTTPInvalidEntity          = ErrInvalidEntity
 	errJWTEncodingError           = errors.New("JWT: Encoding Error")
 	errResponseError              = errors.New("HTTP: Response error")
 	errURLParse                   = errors.New("HTTP: URL Parse Error")
 	errJSONMarshalError           = errors.New("Encoding: JSON Marshal error")
 	errJSONUnmarshalError         = errors.New("Encoding: JSON Unmarshal error")
 	errInvalidLogLevel            = errors.New("Invalid log level")
+	errNoGrant                    = errors.New("Client: No grant available to get a new token.")
+	errInvalidPageSize            = errors.New("Search: PageSize must be greater than 0.")
+	errInvalidRange               = errors.New("HTTP: Invalid range. The offset can't be negative.")
+	errRangeNotSupported          = errors.New("HTTP: The platform returned the whole content instead of the range.")
+	errMissingETag                = errors.New("HTTP: The resource has no ETag.")
+	errModifyNotPointer           = errors.New("Client: The resource to modify must be a non nil pointer.")
+	errInvalidLocation            = errors.New("HTTP: Invalid Location of the created entity.")
+	errInvalidCondition           = errors.New("Search: Invalid condition. It must have one operator and one field.")
+	errSortConflict               = errors.New("Search: A field can't be sorted more than once.")
+	errInvalidSortDirection       = errors.New("Search: Sort direction must be SortAsc or SortDesc.")
+	errAggregationNoFields        = errors.New("Search: At least one field is needed to group by.")
+	errKeysetSort                 = errors.New("Search: Keyset pagination can only sort by its keys in ascending order.")
+	errKeysetMissingKey           = errors.New("Search: Keyset pagination key not found in the results.")
+	errIteratorNoCurrent          = errors.New("Search: Scan called without a current result. Call Next first.")
 )
+
+// Sentinel errors to compare against with errors.Is. Every *APIError matches
+// the sentinel of its status code.
+var (
+	ErrUnauthorized       = errors.New("HTTP: 401 Not authorized")
+	ErrNotFound           = errors.New("HTTP: 404 Not found")
+	ErrConflict           = errors.New("HTTP: 409 Conflict")
+	ErrInvalidEntity      = errors.New("HTTP: 422 Invalid Entity")
+	ErrPreconditionFailed = errors.New("HTTP: 412 Precondition Failed")
+)
+
+// CorbelError is the error document returned by the platform in the body of
+// failed requests.
+type CorbelError struct {
+	Code        string `json:"error"`
+	Description string `json:"errorDescription"`
+}
+
+// APIError is returned when the platform answers with an status code different
+// from the expected one.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Method is the HTTP method of the request.
+	Method string
+	// URL is the requested url.
+	URL string
+	// Corbel is the decoded error document, nil if the body was not one.
+	Corbel *CorbelError
+	// Body is the raw response body.
+	Body []byte
+}
+
+// newAPIError builds an APIError from the response and its already read body
+func newAPIError(res *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Body:       body,
+	}
+	if res.Request != nil {
+		apiErr.Method = res.Request.Method
+		apiErr.URL = res.Request.URL.String()
+	}
+	var corbelErr CorbelError
+	if err := json.Unmarshal(body, &corbelErr); err == nil && (corbelErr.Code != "" || corbelErr.Description != "") {
+		apiErr.Corbel = &corbelErr
+	}
+	return apiErr
+}
+
+// Error keeps the historical "<code> <status text>" format.
+func (e *APIError) Error() string {
+	if http.StatusText(e.StatusCode) == "" {
+		return fmt.Sprintf("HTTP Error %d", e.StatusCode)
+	}
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports whether target is the sentinel error for the status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrInvalidEntity:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package corbel
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// SearchExport downloads all the results of a Search fetching several pages
+// concurrently. Results are delivered in order and the pages are only fetched
+// when there is room for them, so a slow reader slows the export down.
+//
+//	export := search.Export(4)
+//	defer export.Close()
+//	for item := range export.Results() {
+//		var resource ResourceForTest
+//		json.Unmarshal(item, &resource)
+//	}
+//	if err := export.Err(); err != nil {
+//		return err
+//	}
+//
+// The number of pages is computed with CountAll when the export starts, so
+// items added to the search while exporting can be missed or duplicated.
+type SearchExport struct {
+	search  *Search
+	workers int
+	results chan json.RawMessage
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	err     error
+}
+
+// exportPage is a page fetched by an export worker
+type exportPage struct {
+	items []json.RawMessage
+	err   error
+}
+
+// Export starts exporting all the results of the search using up to workers
+// concurrent requests
+func (s *Search) Export(workers int) *SearchExport {
+	return s.ExportContext(context.Background(), workers)
+}
+
+// ExportContext is like Export but uses ctx for the requests.
+func (s *Search) ExportContext(ctx context.Context, workers int) *SearchExport {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	e := &SearchExport{
+		search:  s,
+		workers: workers,
+		results: make(chan json.RawMessage),
+		cancel:  cancel,
+	}
+	go e.run(ctx)
+	return e
+}
+
+// Results returns the channel the results are delivered on. It's closed when
+// the export finishes, fails or is closed.
+func (e *SearchExport) Results() <-chan json.RawMessage {
+	return e.results
+}
+
+// Err returns the first error of the export. It must be called after the
+// results channel is closed.
+func (e *SearchExport) Err() error {
+	return e.err
+}
+
+// Close stops the export and waits for the running requests to finish.
+func (e *SearchExport) Close() {
+	e.cancel()
+	for range e.results {
+	}
+}
+
+// run fetches the pages in order and delivers their results until all of
+// them are sent or the first error
+func (e *SearchExport) run(ctx context.Context) {
+	defer close(e.results)
+	defer e.wg.Wait()
+	defer e.cancel()
+
+	total, err := e.search.CountAllContext(ctx)
+	if err != nil {
+		e.err = err
+		return
+	}
+	if e.search.PageSize <= 0 {
+		e.err = errInvalidPageSize
+		return
+	}
+	pages := (total + e.search.PageSize - 1) / e.search.PageSize
+
+	// slots bounds the pages being fetched or waiting to be delivered
+	slots := make(chan struct{}, e.workers)
+	pending := make(chan chan exportPage, e.workers)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer close(pending)
+		for page := 0; page < pages; page++ {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			fetched := make(chan exportPage, 1)
+			pending <- fetched
+			e.wg.Add(1)
+			go e.fetch(ctx, page, fetched)
+		}
+	}()
+
+	for fetched := range pending {
+		page := <-fetched
+		<-slots
+		if page.err != nil {
+			e.err = page.err
+			return
+		}
+		for _, item := range page.items {
+			select {
+			case e.results <- item:
+			case <-ctx.Done():
+				e.err = ctx.Err()
+				return
+			}
+		}
+	}
+	// the producer stops without error when ctx is done
+	if e.err == nil {
+		e.err = ctx.Err()
+	}
+}
+
+// fetch requests a page of the search
+func (e *SearchExport) fetch(ctx context.Context, page int, fetched chan<- exportPage) {
+	defer e.wg.Done()
+	var items []json.RawMessage
+	err := e.search.PageContext(ctx, page, &items)
+	fetched <- exportPage{items: items, err: err}
+}
@@ -0,0 +1,120 @@
+package corbel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newExportTestClient returns a client whose server has total items with
+// consecutive ids. The page failPage fails with a 500 status code.
+func newExportTestClient(t *testing.T, total, failPage int, maxInFlight *int) *Client {
+	var (
+		mu       sync.Mutex
+		inFlight int
+	)
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api:aggregation") != "" {
+			fmt.Fprintf(w, `{"count":%d}`, total)
+			return
+		}
+
+		mu.Lock()
+		inFlight++
+		if inFlight > *maxInFlight {
+			*maxInFlight = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("api:page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("api:pageSize"))
+		// later pages answer first to check the results are delivered in order
+		time.Sleep(time.Duration(10-page%10) * time.Millisecond)
+		if page == failPage {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		items := []iteratorTestItem{}
+		for id := page * pageSize; id < total && id < (page+1)*pageSize; id++ {
+			items = append(items, iteratorTestItem{ID: id})
+		}
+		json.NewEncoder(w).Encode(items)
+	}))
+}
+
+func TestSearchExport(t *testing.T) {
+	var maxInFlight int
+	client := newExportTestClient(t, 103, -1, &maxInFlight)
+	search := client.Resources.SearchCollection("test:Collection")
+
+	export := search.Export(3)
+	defer export.Close()
+
+	count := 0
+	for item := range export.Results() {
+		var result iteratorTestItem
+		if err := json.Unmarshal(item, &result); err != nil {
+			t.Fatalf("Error decoding result: %v", err)
+		}
+		if got, want := result.ID, count; got != want {
+			t.Fatalf("Error in result order. Got: %v, Want: %v", got, want)
+		}
+		count++
+	}
+	if err := export.Err(); err != nil {
+		t.Errorf("Export returned error: %v", err)
+	}
+	if got, want := count, 103; got != want {
+		t.Errorf("Error in results exported. Got: %v, Want: %v", got, want)
+	}
+	if maxInFlight > 3 {
+		t.Errorf("Export must not run more than 3 requests at once. Got: %v", maxInFlight)
+	}
+}
+
+func TestSearchExportError(t *testing.T) {
+	var maxInFlight int
+	client := newExportTestClient(t, 100, 4, &maxInFlight)
+	search := client.Resources.SearchCollection("test:Collection")
+
+	export := search.Export(2)
+	count := 0
+	for range export.Results() {
+		count++
+	}
+	var apiErr *APIError
+	if err := export.Err(); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Export must fail with the error of the failed page. Got: %v", err)
+	}
+	if got, want := count, 40; got != want {
+		t.Errorf("Export must deliver the results before the failed page. Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestSearchExportClose(t *testing.T) {
+	var maxInFlight int
+	client := newExportTestClient(t, 1000, -1, &maxInFlight)
+	search := client.Resources.SearchCollection("test:Collection")
+
+	export := search.Export(4)
+	<-export.Results()
+	export.Close()
+
+	if _, ok := <-export.Results(); ok {
+		t.Error("Results must be closed after Close")
+	}
+	if export.Err() == nil {
+		t.Error("Err must return an error if the export was closed before finishing")
+	}
+}
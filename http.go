@@ -2,38 +2,60 @@ package corbel
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 
-	"github.com/Sirupsen/logrus"
 	"github.com/google/go-querystring/query"
 )
 
 // NewRequestContentType creates an API request.
+// It is a shortcut for NewRequestContentTypeContext using context.Background().
+func (c *Client) NewRequestContentType(method, endpoint, urlStr, headerContentType, headerAccept string, body interface{}) (*http.Request, error) {
+	return c.NewRequestContentTypeContext(context.Background(), method, endpoint, urlStr, headerContentType, headerAccept, body)
+}
+
+// NewRequestContentTypeContext creates an API request bound to ctx.
+// ctx is used both for the request itself and for the token refresh if needed.
 // method is the HTTP method to use
 // endpoint is the endpoint of SR to speak with
 // urlStr is the url to query. it must be preceded by a slash.
 // headerContentType is the header['Content-Type'] of the request.
 // headerAccept is the header['Accept'] of the request.
 // body is, if specified, the value JSON encoded to be used as request body.
-func (c *Client) NewRequestContentType(method, endpoint, urlStr, headerContentType, headerAccept string, body interface{}) (*http.Request, error) {
+// If body is an io.Reader it's sent as is instead, streaming it without
+// reading it in memory.
+func (c *Client) NewRequestContentTypeContext(ctx context.Context, method, endpoint, urlStr, headerContentType, headerAccept string, body interface{}) (*http.Request, error) {
 	url, _ := url.Parse(c.URLFor(endpoint, urlStr))
-	buf := new(bytes.Buffer)
-	if body != nil {
-		if err := json.NewEncoder(buf).Encode(body); err != nil {
-			c.logger.Debugf("failed to encode body: %v", err)
-			return nil, errJSONMarshalError
+	var (
+		reqCtx  = ctx
+		reqBody io.Reader
+		logBody string
+	)
+	if reader, ok := body.(io.Reader); ok {
+		reqCtx = withStreaming(ctx)
+		reqBody, logBody = reader, "<stream>"
+	} else {
+		buf := new(bytes.Buffer)
+		if body != nil {
+			if err := json.NewEncoder(buf).Encode(body); err != nil {
+				c.logger.Debugf("failed to encode body: %v", err)
+				return nil, errJSONMarshalError
+			}
 		}
+		reqBody, logBody = buf, buf.String()
 	}
 
-	c.logger.WithFields(logrus.Fields{
-		"method": method, "accept": headerAccept, "url": url.String(), "body": buf.String(),
+	c.logger.WithFields(Fields{
+		"method": method, "accept": headerAccept, "url": url.String(), "body": logBody,
 	}).Debug("new request")
-	req, err := http.NewRequest(method, url.String(), buf)
+	req, err := http.NewRequestWithContext(reqCtx, method, url.String(), reqBody)
 	if err != nil {
 		c.logger.Debugf("failed to create request: %v", err)
 		return nil, err
@@ -42,7 +64,12 @@ func (c *Client) NewRequestContentType(method, endpoint, urlStr, headerContentTy
 	req.Header.Add("Content-Type", headerContentType)
 	req.Header.Add("Accept", headerAccept)
 	req.Header.Add("User-Agent", c.UserAgent)
-	if token := c.Token(); token != "" {
+	token, err := c.TokenContext(ctx)
+	if err != nil {
+		c.logger.Debugf("failed to get token: %v", err)
+		return nil, err
+	}
+	if token != "" {
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
 	c.logger.Debugf("request headers: %v", req.Header)
@@ -55,43 +82,156 @@ func (c *Client) NewRequestContentType(method, endpoint, urlStr, headerContentTy
 // url is the url to query. it must be preceded by a slash.
 // body is, if specified, the value JSON encoded to be used as request body.
 func (c *Client) NewRequest(method, endpoint, url string, body interface{}) (*http.Request, error) {
-	return c.NewRequestContentType(method, endpoint, url, "application/json", "application/json", body)
+	return c.NewRequestContext(context.Background(), method, endpoint, url, body)
+}
+
+// NewRequestContext creates an API request bound to ctx using 'application/json'.
+// method is the HTTP method to use
+// endpoint is the endpoint of SR to speak with
+// url is the url to query. it must be preceded by a slash.
+// body is, if specified, the value JSON encoded to be used as request body.
+func (c *Client) NewRequestContext(ctx context.Context, method, endpoint, url string, body interface{}) (*http.Request, error) {
+	return c.NewRequestContentTypeContext(ctx, method, endpoint, url, "application/json", "application/json", body)
 }
 
 func returnErrorHTTPInterface(client *Client, req *http.Request, errr error, object interface{}, desiredStatusCode int) (string, error) {
+	_, location, err := returnResponseHTTPInterface(client, req, errr, object, desiredStatusCode)
+	return location, err
+}
+
+// returnResponseHTTPInterface is like returnErrorHTTPInterface but also
+// returns the response, with its body already read and closed, so its headers
+// can be used.
+func returnResponseHTTPInterface(client *Client, req *http.Request, errr error, object interface{}, desiredStatusCode int) (*http.Response, string, error) {
 	if errr != nil {
-		return "", errr
+		return nil, "", errr
 	}
 
-	res, err := client.httpClient.Do(req)
+	res, err := client.do(req)
 	if err != nil {
 		client.logger.Debugf("failed to make request: %v", err)
-		return "", err
+		return nil, "", err
 	}
 	objectByte, err := ioutil.ReadAll(res.Body)
 	defer res.Body.Close()
+	client.logger.WithFields(Fields{
+		"method": res.Request.Method, "url": res.Request.URL.String(),
+		"code": res.StatusCode, "status": res.Status, "body": string(objectByte),
+	}).Debug("response received")
+	location, errStatus := returnErrorByHTTPStatusCode(res, objectByte, desiredStatusCode)
+	if errStatus != nil {
+		return res, location, errStatus
+	}
 	if object != nil {
 		if err != nil {
-			return "", errResponseError
+			return res, "", errResponseError
 		}
 		if err = json.Unmarshal(objectByte, &object); err != nil {
-			return "", errJSONUnmarshalError
+			return res, "", errJSONUnmarshalError
 		}
 	}
-	client.logger.WithFields(logrus.Fields{
-		"method": res.Request.Method, "url": res.Request.URL.String(),
-		"code": res.StatusCode, "status": res.Status, "body": string(objectByte),
-	}).Debug("response received")
-	return returnErrorByHTTPStatusCode(res, desiredStatusCode)
+	return res, location, nil
+}
+
+// do sends the request using the Cache, if any. GET requests without body are
+// answered from the cache when possible, and any other request removes the
+// cached responses of its collection since it may modify them.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	switch {
+	case c.Cache == nil:
+		return c.doAuthenticated(req)
+	case req.Method == "GET" && (req.Body == nil || req.Body == http.NoBody) && req.Header.Get("Range") == "" && !isStreaming(req):
+		return c.doCached(req)
+	case req.Method != "GET" && req.Method != "HEAD":
+		defer c.invalidateCache(req.URL)
+	}
+	return c.doAuthenticated(req)
+}
+
+// doAuthenticated sends the request applying the RetryPolicy. If the platform
+// rejects the token of an authenticated request, it gets a new one running
+// again the last grant and sends the request once more.
+func (c *Client) doAuthenticated(req *http.Request) (*http.Response, error) {
+	if err := bufferRequestBody(req); err != nil {
+		return nil, err
+	}
+
+	res, err := c.send(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	stale := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if stale == "" {
+		return res, nil
+	}
+	if err := c.replaceToken(req.Context(), stale, c.renewRevokedToken); err != nil {
+		c.logger.Debugf("failed to authenticate again: %v", err)
+		return res, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return res, nil
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		// a streamed body was already sent and can't be sent again
+		return res, nil
+	}
+	c.tokenMu.Lock()
+	token := c.CurrentToken
+	c.tokenMu.Unlock()
+	retry.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	res.Body.Close()
+	c.logger.Debug("sending request again with the new token")
+	return c.send(retry)
+}
+
+// streamingKey is the context key marking the requests whose bodies are
+// streamed
+type streamingKey struct{}
+
+// withStreaming returns a copy of ctx marking the requests using it as
+// streamed: neither their bodies are read in memory to send them again nor
+// their responses are cached.
+func withStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingKey{}, true)
+}
+
+// isStreaming reports if the request was marked with withStreaming
+func isStreaming(req *http.Request) bool {
+	streaming, _ := req.Context().Value(streamingKey{}).(bool)
+	return streaming
+}
+
+// bufferRequestBody reads the body of the request in memory, if it can not be
+// already read again, so the request can be sent more than once. Streamed
+// bodies are left as they are, so they can only be sent once.
+func bufferRequestBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil || isStreaming(req) {
+		return nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
 }
 
 func returnErrorHTTPSimple(client *Client, req *http.Request, err error, desiredStatusCode int) (string, error) {
 	return returnErrorHTTPInterface(client, req, err, nil, desiredStatusCode)
 }
 
-// returnErrorByHTTPStatusCode returns the http error code or nil if it returns the
-// desired error
-func returnErrorByHTTPStatusCode(res *http.Response, desiredStatusCode int) (string, error) {
+// returnErrorByHTTPStatusCode returns an *APIError built from the response and
+// its body or nil if it returns the desired status code
+func returnErrorByHTTPStatusCode(res *http.Response, body []byte, desiredStatusCode int) (string, error) {
 	location, _ := res.Location()
 	locationString := ""
 	if location != nil {
@@ -102,9 +242,9 @@ func returnErrorByHTTPStatusCode(res *http.Response, desiredStatusCode int) (str
 		return locationString, nil
 	}
 	if http.StatusText(res.StatusCode) == "" {
-		return "", fmt.Errorf("HTTP Error %d", res.StatusCode)
+		return "", newAPIError(res, body)
 	}
-	return locationString, fmt.Errorf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	return locationString, newAPIError(res, body)
 }
 
 func addOptions(s string, opt interface{}) (string, error) {
@@ -2,9 +2,14 @@ package corbel
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
 )
 
 func TestClientNewRequest(t *testing.T) {
@@ -62,3 +67,121 @@ func TestClientNewRequest(t *testing.T) {
 		t.Errorf("/version unmarshaled json build.groupId is %v, but want %v", got, want)
 	}
 }
+
+func TestReturnErrorHTTPInterfaceAPIError(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/resource/test:Collection/missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"not_found","errorDescription":"Resource not found"}`)
+		case "/v1.0/resource/test:Collection/conflict":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `plain text`)
+		}
+	}))
+
+	var resource map[string]interface{}
+	err := client.Resources.GetFromCollection("test:Collection", "missing", &resource)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetFromCollection must return ErrNotFound. Got: %v", err)
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Errorf("A 404 must not match ErrConflict")
+	}
+	if got, want := err.Error(), "404 Not Found"; got != want {
+		t.Errorf("APIError message is %v, but want %v", got, want)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetFromCollection must return an *APIError. Got: %T", err)
+	}
+	if got, want := apiErr.Method, "GET"; got != want {
+		t.Errorf("APIError Method is %v, but want %v", got, want)
+	}
+	if got, want := apiErr.URL, client.URLFor("resources", "/v1.0/resource/test:Collection/missing"); got != want {
+		t.Errorf("APIError URL is %v, but want %v", got, want)
+	}
+	if apiErr.Corbel == nil {
+		t.Fatalf("APIError must decode the Corbel error document")
+	}
+	if got, want := apiErr.Corbel.Code, "not_found"; got != want {
+		t.Errorf("APIError Corbel.Code is %v, but want %v", got, want)
+	}
+	if got, want := apiErr.Corbel.Description, "Resource not found"; got != want {
+		t.Errorf("APIError Corbel.Description is %v, but want %v", got, want)
+	}
+
+	err = client.Resources.DeleteFromCollection("test:Collection", "conflict")
+	if !errors.Is(err, ErrConflict) || !errors.Is(err, errHTTPConflict) {
+		t.Fatalf("DeleteFromCollection must return ErrConflict. Got: %v", err)
+	}
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("DeleteFromCollection must return an *APIError. Got: %T", err)
+	}
+	if apiErr.Corbel != nil {
+		t.Errorf("APIError Corbel must be nil if the body is not an error document. Got: %v", apiErr.Corbel)
+	}
+	if got, want := string(apiErr.Body), "plain text"; got != want {
+		t.Errorf("APIError Body is %v, but want %v", got, want)
+	}
+}
+
+func TestClientReauthenticateOnUnauthorized(t *testing.T) {
+	var tokens int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/oauth/token":
+			tokens++
+			assertion, _ := jwt.Parse(r.FormValue("assertion"), func(token *jwt.Token) (interface{}, error) {
+				return []byte("someSecret"), nil
+			})
+			if got, want := assertion.Claims["basic_auth.username"], "username"; got != want {
+				t.Errorf("Grant username is %v, but want %v", got, want)
+			}
+			fmt.Fprintf(w, `{"accessToken":"token%d","expiresAt":%d}`, tokens, time.Now().Add(time.Hour).Unix()*1000)
+		case "/v1.0/resource/test:Collection/id":
+			if r.Header.Get("Authorization") != "Bearer token2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			if got, want := string(body), "{\"key\":\"value\"}\n"; got != want {
+				t.Errorf("Replayed body is %v, but want %v", got, want)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	if err := client.IAM.OauthTokenBasicAuth("username", "password"); err != nil {
+		t.Fatalf("OauthTokenBasicAuth must not fail. Got: %v", err)
+	}
+
+	err := client.Resources.UpdateInCollection("test:Collection", "id", map[string]string{"key": "value"})
+	if err != nil {
+		t.Errorf("UpdateInCollection must be replayed with a new token. Got: %v", err)
+	}
+	if got, want := tokens, 2; got != want {
+		t.Errorf("Grant requests are %v, but want %v", got, want)
+	}
+	if got, want := client.Token(), "token2"; got != want {
+		t.Errorf("Token is %v, but want %v", got, want)
+	}
+}
+
+func TestClientReauthenticateWithoutGrant(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1.0/oauth/token" {
+			t.Errorf("No grant must be requested without a previous one")
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	client.CurrentToken = "token"
+	client.CurrentTokenExpiresAt = time.Now().Add(time.Hour).Unix() * 1000
+
+	err := client.Resources.DeleteFromCollection("test:Collection", "id")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("DeleteFromCollection must fail with ErrUnauthorized. Got: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package corbel
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -69,47 +70,86 @@ type IAMScope struct {
 
 // DomainAdd adds an Domain defined struct to the platform
 func (i *IAMService) DomainAdd(domain *IAMDomain) (string, error) {
+	return i.DomainAddContext(context.Background(), domain)
+}
+
+// DomainAddContext is like DomainAdd but uses ctx for the request.
+func (i *IAMService) DomainAddContext(ctx context.Context, domain *IAMDomain) (string, error) {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("POST", "iam", "/v1.0/domain/", domain)
+	req, err = i.client.NewRequestContext(ctx, "POST", "iam", "/v1.0/domain/", domain)
 	return returnErrorHTTPSimple(i.client, req, err, 201)
 }
 
+// DomainAddCreated is like DomainAdd but returns the parsed location of the
+// created domain. If fetched is not nil the created domain is also got into it.
+func (i *IAMService) DomainAddCreated(domain, fetched *IAMDomain) (*Created, error) {
+	return i.DomainAddCreatedContext(context.Background(), domain, fetched)
+}
+
+// DomainAddCreatedContext is like DomainAddCreated but uses ctx for the requests.
+func (i *IAMService) DomainAddCreatedContext(ctx context.Context, domain, fetched *IAMDomain) (*Created, error) {
+	location, err := i.DomainAddContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	created, err := parseCreated(location)
+	if err != nil || fetched == nil {
+		return created, err
+	}
+	return created, i.DomainGetContext(ctx, created.ID, fetched)
+}
+
 // DomainUpdate updates an domain by using IAMDomain
 func (i *IAMService) DomainUpdate(id string, domain *IAMDomain) error {
+	return i.DomainUpdateContext(context.Background(), id, domain)
+}
+
+// DomainUpdateContext is like DomainUpdate but uses ctx for the request.
+func (i *IAMService) DomainUpdateContext(ctx context.Context, id string, domain *IAMDomain) error {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("PUT", "iam", fmt.Sprintf("/v1.0/domain/%s", id), domain)
+	req, err = i.client.NewRequestContext(ctx, "PUT", "iam", fmt.Sprintf("/v1.0/domain/%s", id), domain)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
 
 // DomainGet gets the desired IAMUdomain from the domain by id
 func (i *IAMService) DomainGet(id string, domain *IAMDomain) error {
+	return i.DomainGetContext(context.Background(), id, domain)
+}
+
+// DomainGetContext is like DomainGet but uses ctx for the request.
+func (i *IAMService) DomainGetContext(ctx context.Context, id string, domain *IAMDomain) error {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("GET", "iam", fmt.Sprintf("/v1.0/domain/%s", id), nil)
+	req, err = i.client.NewRequestContext(ctx, "GET", "iam", fmt.Sprintf("/v1.0/domain/%s", id), nil)
 	_, err = returnErrorHTTPInterface(i.client, req, err, domain, 200)
 	return err
 }
 
 // DomainDelete deletes the desired domain from IAM by id
 func (i *IAMService) DomainDelete(id string) error {
+	return i.DomainDeleteContext(context.Background(), id)
+}
+
+// DomainDeleteContext is like DomainDelete but uses ctx for the request.
+func (i *IAMService) DomainDeleteContext(ctx context.Context, id string) error {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("DELETE", "iam", fmt.Sprintf("/v1.0/domain/%s", id), nil)
+	req, err = i.client.NewRequestContext(ctx, "DELETE", "iam", fmt.Sprintf("/v1.0/domain/%s", id), nil)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
@@ -121,47 +161,86 @@ func (i *IAMService) DomainSearch() *Search {
 
 // ClientAdd adds an Client defined struct to the platform
 func (i *IAMService) ClientAdd(client *IAMClient) (string, error) {
+	return i.ClientAddContext(context.Background(), client)
+}
+
+// ClientAddContext is like ClientAdd but uses ctx for the request.
+func (i *IAMService) ClientAddContext(ctx context.Context, client *IAMClient) (string, error) {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("POST", "iam", fmt.Sprintf("/v1.0/domain/%s/client/", client.Domain), client)
+	req, err = i.client.NewRequestContext(ctx, "POST", "iam", fmt.Sprintf("/v1.0/domain/%s/client/", client.Domain), client)
 	return returnErrorHTTPSimple(i.client, req, err, 201)
 }
 
+// ClientAddCreated is like ClientAdd but returns the parsed location of the
+// created client. If fetched is not nil the created client is also got into it.
+func (i *IAMService) ClientAddCreated(client, fetched *IAMClient) (*Created, error) {
+	return i.ClientAddCreatedContext(context.Background(), client, fetched)
+}
+
+// ClientAddCreatedContext is like ClientAddCreated but uses ctx for the requests.
+func (i *IAMService) ClientAddCreatedContext(ctx context.Context, client, fetched *IAMClient) (*Created, error) {
+	location, err := i.ClientAddContext(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	created, err := parseCreated(location)
+	if err != nil || fetched == nil {
+		return created, err
+	}
+	return created, i.ClientGetContext(ctx, client.Domain, created.ID, fetched)
+}
+
 // ClientUpdate updates an client by using IAMClient
 func (i *IAMService) ClientUpdate(id string, client *IAMClient) error {
+	return i.ClientUpdateContext(context.Background(), id, client)
+}
+
+// ClientUpdateContext is like ClientUpdate but uses ctx for the request.
+func (i *IAMService) ClientUpdateContext(ctx context.Context, id string, client *IAMClient) error {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("PUT", "iam", fmt.Sprintf("/v1.0/domain/%s/client/%s", client.Domain, id), client)
+	req, err = i.client.NewRequestContext(ctx, "PUT", "iam", fmt.Sprintf("/v1.0/domain/%s/client/%s", client.Domain, id), client)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
 
 // ClientGet gets the desired IAMClient
 func (i *IAMService) ClientGet(domainName, id string, client *IAMClient) error {
+	return i.ClientGetContext(context.Background(), domainName, id, client)
+}
+
+// ClientGetContext is like ClientGet but uses ctx for the request.
+func (i *IAMService) ClientGetContext(ctx context.Context, domainName, id string, client *IAMClient) error {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("GET", "iam", fmt.Sprintf("/v1.0/domain/%s/client/%s", domainName, id), nil)
+	req, err = i.client.NewRequestContext(ctx, "GET", "iam", fmt.Sprintf("/v1.0/domain/%s/client/%s", domainName, id), nil)
 	_, err = returnErrorHTTPInterface(i.client, req, err, client, 200)
 	return err
 }
 
 // ClientDelete deletes the desired client from IAM by id
 func (i *IAMService) ClientDelete(domainName, id string) error {
+	return i.ClientDeleteContext(context.Background(), domainName, id)
+}
+
+// ClientDeleteContext is like ClientDelete but uses ctx for the request.
+func (i *IAMService) ClientDeleteContext(ctx context.Context, domainName, id string) error {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("DELETE", "iam", fmt.Sprintf("/v1.0/domain/%s/client/%s", domainName, id), nil)
+	req, err = i.client.NewRequestContext(ctx, "DELETE", "iam", fmt.Sprintf("/v1.0/domain/%s/client/%s", domainName, id), nil)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
@@ -173,40 +252,60 @@ func (i *IAMService) ClientSearch(domainName string) *Search {
 
 // ScopeAdd adds an Scope defined struct to the platform
 func (i *IAMService) ScopeAdd(scope *IAMScope) (string, error) {
+	return i.ScopeAddContext(context.Background(), scope)
+}
+
+// ScopeAddContext is like ScopeAdd but uses ctx for the request.
+func (i *IAMService) ScopeAddContext(ctx context.Context, scope *IAMScope) (string, error) {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("POST", "iam", "/v1.0/scope/", scope)
+	req, err = i.client.NewRequestContext(ctx, "POST", "iam", "/v1.0/scope/", scope)
 	return returnErrorHTTPSimple(i.client, req, err, 201)
 }
 
 // ScopeUpdate updates an scope by using IAMScope
 func (i *IAMService) ScopeUpdate(scope *IAMScope) (string, error) {
-	return i.ScopeAdd(scope)
+	return i.ScopeUpdateContext(context.Background(), scope)
+}
+
+// ScopeUpdateContext is like ScopeUpdate but uses ctx for the request.
+func (i *IAMService) ScopeUpdateContext(ctx context.Context, scope *IAMScope) (string, error) {
+	return i.ScopeAddContext(ctx, scope)
 }
 
 // ScopeGet gets the desired IAMScope from the scope by id
 func (i *IAMService) ScopeGet(id string, scope *IAMScope) error {
+	return i.ScopeGetContext(context.Background(), id, scope)
+}
+
+// ScopeGetContext is like ScopeGet but uses ctx for the request.
+func (i *IAMService) ScopeGetContext(ctx context.Context, id string, scope *IAMScope) error {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("GET", "iam", fmt.Sprintf("/v1.0/scope/%s", id), nil)
+	req, err = i.client.NewRequestContext(ctx, "GET", "iam", fmt.Sprintf("/v1.0/scope/%s", id), nil)
 	_, err = returnErrorHTTPInterface(i.client, req, err, scope, 200)
 	return err
 }
 
 // ScopeDelete deletes the desired scope from IAM by id
 func (i *IAMService) ScopeDelete(id string) error {
+	return i.ScopeDeleteContext(context.Background(), id)
+}
+
+// ScopeDeleteContext is like ScopeDelete but uses ctx for the request.
+func (i *IAMService) ScopeDeleteContext(ctx context.Context, id string) error {
 	var (
 		req *http.Request
 		err error
 	)
 
-	req, err = i.client.NewRequest("DELETE", "iam", fmt.Sprintf("/v1.0/scope/%s", id), nil)
+	req, err = i.client.NewRequestContext(ctx, "DELETE", "iam", fmt.Sprintf("/v1.0/scope/%s", id), nil)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
@@ -2,6 +2,7 @@ package corbel
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -20,34 +21,64 @@ type iamOauthTokenResponse struct {
 //
 // API Docs: http://docs.silkroadiam.apiary.io/#reference/authorization/oauthtoken
 func (i *IAMService) OauthToken() error {
+	return i.OauthTokenContext(context.Background())
+}
+
+// OauthTokenContext is like OauthToken but uses ctx for the request.
+func (i *IAMService) OauthTokenContext(ctx context.Context) error {
 	i.client.logger.Debug("requesting OauthToken")
-	return i.OauthTokenBasicAuth("", "")
+	return i.OauthTokenBasicAuthContext(ctx, "", "")
 }
 
 // RefreshToken gets an access token
 //
 // API Docs: http://docs.silkroadiam.apiary.io/#reference/authorization/oauthtoken
 func (i *IAMService) RefreshToken() error {
+	return i.RefreshTokenContext(context.Background())
+}
+
+// RefreshTokenContext is like RefreshToken but uses ctx for the request.
+func (i *IAMService) RefreshTokenContext(ctx context.Context) error {
 	i.client.logger.Debug("refreshing token")
+	i.client.tokenMu.Lock()
+	refreshToken := i.client.CurrentRefreshToken
+	i.client.tokenMu.Unlock()
+
 	token := i.newToken()
-	token.Claims["refresh_token"] = i.client.CurrentRefreshToken
+	token.Claims["refresh_token"] = refreshToken
 	// fmt.Println("token:", token)
-	return i.auth(token)
+	return i.auth(ctx, token)
 }
 
 //OauthTokenPrn get user access token to use it
 func (i *IAMService) OauthTokenPrn(username string) error {
-	i.client.logger.Debugf("requesting OauthTokenPrn for %s", username)
+	return i.OauthTokenPrnContext(context.Background(), username)
+}
+
+// OauthTokenPrnContext is like OauthTokenPrn but uses ctx for the request.
+func (i *IAMService) OauthTokenPrnContext(ctx context.Context, username string) error {
+	i.client.logger.WithFields(Fields{"prn": username}).Debug("requesting OauthTokenPrn")
 	token := i.newToken()
 	token.Claims["prn"] = username
-	return i.auth(token)
+	if err := i.auth(ctx, token); err != nil {
+		return err
+	}
+	i.client.setGrant(func(ctx context.Context) error {
+		return i.OauthTokenPrnContext(ctx, username)
+	})
+	return nil
 }
 
 // OauthTokenBasicAuth gets an access token using username/password scheme (basic auth)
 //
 // API Docs: http://docs.silkroadiam.apiary.io/#reference/authorization/oauthtoken
 func (i *IAMService) OauthTokenBasicAuth(username, password string) error {
-	i.client.logger.Debugf("requesting OauthTokenBasicAuth for %s", username)
+	return i.OauthTokenBasicAuthContext(context.Background(), username, password)
+}
+
+// OauthTokenBasicAuthContext is like OauthTokenBasicAuth but uses ctx for the request.
+func (i *IAMService) OauthTokenBasicAuthContext(ctx context.Context, username, password string) error {
+	i.client.logger.WithFields(Fields{"basic_auth.username": username}).Debug("requesting OauthTokenBasicAuth")
 	token := i.newToken()
 	// looking for basic auth pair
 	if username != "" {
@@ -56,13 +87,19 @@ func (i *IAMService) OauthTokenBasicAuth(username, password string) error {
 	if password != "" {
 		token.Claims["basic_auth.password"] = password
 	}
-	return i.auth(token)
+	if err := i.auth(ctx, token); err != nil {
+		return err
+	}
+	i.client.setGrant(func(ctx context.Context) error {
+		return i.OauthTokenBasicAuthContext(ctx, username, password)
+	})
+	return nil
 }
 
-func (i *IAMService) auth(token *jwt.Token) error {
+func (i *IAMService) auth(ctx context.Context, token *jwt.Token) error {
 	// Sign and get the complete encoded token as a string
-	tokenString, err := token.SignedString([]byte(i.client.ClientSecret))
-	i.client.logger.Debugf("token: %s", tokenString)
+	tokenString, err := token.SignedString(i.client.signingKey)
+	i.client.logger.WithFields(Fields{"assertion": tokenString}).Debug("signed assertion")
 	if err != nil {
 		return errJWTEncodingError
 	}
@@ -71,7 +108,7 @@ func (i *IAMService) auth(token *jwt.Token) error {
 	values.Set("grant_type", grantType)
 	values.Set("assertion", tokenString)
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s", i.client.URLFor("iam", "/v1.0/oauth/token")), bytes.NewBufferString(values.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s", i.client.URLFor("iam", "/v1.0/oauth/token")), bytes.NewBufferString(values.Encode()))
 	if err != nil {
 		return err
 	}
@@ -83,10 +120,10 @@ func (i *IAMService) auth(token *jwt.Token) error {
 		return err
 	}
 
-	i.client.logger.Debugf("upgrading token. Access token: %s, Refresh token: %s", iamResponse.AccessToken, iamResponse.RefreshToken)
-	i.client.CurrentToken = iamResponse.AccessToken
-	i.client.CurrentTokenExpiresAt = iamResponse.ExpiresAt
-	i.client.CurrentRefreshToken = iamResponse.RefreshToken
+	i.client.logger.WithFields(Fields{
+		"accessToken": iamResponse.AccessToken, "refreshToken": iamResponse.RefreshToken,
+	}).Debug("upgrading token")
+	i.client.setToken(iamResponse.AccessToken, iamResponse.RefreshToken, iamResponse.ExpiresAt)
 	return nil
 }
 
@@ -111,6 +148,11 @@ func (i *IAMService) newToken() *jwt.Token {
 //
 // API Docs: http://docs.silkroadiam.apiary.io/#reference/authorization/oauthtokenupgrade
 func (i *IAMService) OauthTokenUpgrade(assetsToken string) error {
+	return i.OauthTokenUpgradeContext(context.Background(), assetsToken)
+}
+
+// OauthTokenUpgradeContext is like OauthTokenUpgrade but uses ctx for the request.
+func (i *IAMService) OauthTokenUpgradeContext(ctx context.Context, assetsToken string) error {
 	var (
 		err    error
 		req    *http.Request
@@ -120,15 +162,17 @@ func (i *IAMService) OauthTokenUpgrade(assetsToken string) error {
 	//values := url.Values{}
 	values.Set("grant_type", grantType)
 	values.Set("assertion", assetsToken)
-	req, _ = http.NewRequest("GET", fmt.Sprintf("%s", i.client.URLFor("iam", "/v1.0/oauth/token/upgrade")),
+	req, _ = http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s", i.client.URLFor("iam", "/v1.0/oauth/token/upgrade")),
 		bytes.NewBufferString(values.Encode()))
 
 	req.Header.Add("User-Agent", userAgent)
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	res, err = i.client.httpClient.Do(req)
-	if res.StatusCode == 401 {
-		return errHTTPNotAuthorized
+	res, _, err = returnResponseHTTPInterface(i.client, req, nil, nil, http.StatusNoContent)
+	// the token is upgraded by any successful response, with or without body
+	if err != nil && (res == nil || res.StatusCode/100 != 2) {
+		return err
 	}
-	return err
+	i.client.tokenUpgraded()
+	return nil
 }
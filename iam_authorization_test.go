@@ -1,6 +1,7 @@
 package corbel
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -76,7 +77,7 @@ func TestIAMOauthTokenUpgrade(t *testing.T) {
 		10, "info")
 
 	err = client.IAM.OauthTokenUpgrade("aaaaaa")
-	if err != errHTTPNotAuthorized {
+	if !errors.Is(err, errHTTPNotAuthorized) {
 		t.Errorf("OauthTokenUpgrade must fail since it got an invalid token. %s", err)
 	}
 
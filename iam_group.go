@@ -1,6 +1,9 @@
 package corbel
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // IAMGroup is the representation of a Group object used by IAM
 type IAMGroup struct {
@@ -11,73 +14,132 @@ type IAMGroup struct {
 
 //UserAddGroups add groups to user's list of groups
 func (i *IAMService) UserAddGroups(userID string, groupIDs []string) error {
+	return i.UserAddGroupsContext(context.Background(), userID, groupIDs)
+}
+
+// UserAddGroupsContext is like UserAddGroups but uses ctx for the request.
+func (i *IAMService) UserAddGroupsContext(ctx context.Context, userID string, groupIDs []string) error {
 	if userID == "" {
 		return errIdentifierEmpty
 	}
-	req, err := i.client.NewRequest("PUT", "iam", fmt.Sprintf("/v1.0/user/%s/groups", userID), groupIDs)
+	req, err := i.client.NewRequestContext(ctx, "PUT", "iam", fmt.Sprintf("/v1.0/user/%s/groups", userID), groupIDs)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
 
 //UserDeleteGroup deletes a groups from the user group list
 func (i *IAMService) UserDeleteGroup(userID, groupID string) error {
+	return i.UserDeleteGroupContext(context.Background(), userID, groupID)
+}
+
+// UserDeleteGroupContext is like UserDeleteGroup but uses ctx for the request.
+func (i *IAMService) UserDeleteGroupContext(ctx context.Context, userID, groupID string) error {
 	if userID == "" || groupID == "" {
 		return errIdentifierEmpty
 	}
-	req, err := i.client.NewRequest("DELETE", "iam", fmt.Sprintf("/v1.0/user/%s/groups/%s", userID, groupID), nil)
+	req, err := i.client.NewRequestContext(ctx, "DELETE", "iam", fmt.Sprintf("/v1.0/user/%s/groups/%s", userID, groupID), nil)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
 
 // GroupAdd adds a new group to iam into the current domain
 func (i *IAMService) GroupAdd(group *IAMGroup) (string, error) {
-	req, err := i.client.NewRequest("POST", "iam", "/v1.0/group", group)
+	return i.GroupAddContext(context.Background(), group)
+}
+
+// GroupAddContext is like GroupAdd but uses ctx for the request.
+func (i *IAMService) GroupAddContext(ctx context.Context, group *IAMGroup) (string, error) {
+	req, err := i.client.NewRequestContext(ctx, "POST", "iam", "/v1.0/group", group)
 	return returnErrorHTTPSimple(i.client, req, err, 201)
 }
 
+// GroupAddCreated is like GroupAdd but returns the parsed location of the
+// created group. If fetched is not nil the created group is also got into it.
+func (i *IAMService) GroupAddCreated(group, fetched *IAMGroup) (*Created, error) {
+	return i.GroupAddCreatedContext(context.Background(), group, fetched)
+}
+
+// GroupAddCreatedContext is like GroupAddCreated but uses ctx for the requests.
+func (i *IAMService) GroupAddCreatedContext(ctx context.Context, group, fetched *IAMGroup) (*Created, error) {
+	location, err := i.GroupAddContext(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+	created, err := parseCreated(location)
+	if err != nil || fetched == nil {
+		return created, err
+	}
+	return created, i.GroupGetContext(ctx, created.ID, fetched)
+}
+
 // GroupGetAll gets all Groups of the client current domain
 func (i *IAMService) GroupGetAll(groups []*IAMGroup) error {
-	req, err := i.client.NewRequest("GET", "iam", "/v1.0/group", nil)
+	return i.GroupGetAllContext(context.Background(), groups)
+}
+
+// GroupGetAllContext is like GroupGetAll but uses ctx for the request.
+func (i *IAMService) GroupGetAllContext(ctx context.Context, groups []*IAMGroup) error {
+	req, err := i.client.NewRequestContext(ctx, "GET", "iam", "/v1.0/group", nil)
 	_, err = returnErrorHTTPInterface(i.client, req, err, groups, 200)
 	return err
 }
 
 // GroupGet gets the desired IAMGroup from the domain by id
 func (i *IAMService) GroupGet(id string, group *IAMGroup) error {
+	return i.GroupGetContext(context.Background(), id, group)
+}
+
+// GroupGetContext is like GroupGet but uses ctx for the request.
+func (i *IAMService) GroupGetContext(ctx context.Context, id string, group *IAMGroup) error {
 	if id == "" {
 		return errIdentifierEmpty
 	}
-	req, err := i.client.NewRequest("GET", "iam", fmt.Sprintf("/v1.0/group/%s", id), nil)
+	req, err := i.client.NewRequestContext(ctx, "GET", "iam", fmt.Sprintf("/v1.0/group/%s", id), nil)
 	_, err = returnErrorHTTPInterface(i.client, req, err, group, 200)
 	return err
 }
 
 // GroupDelete deletes the desired group from IAM by id
 func (i *IAMService) GroupDelete(id string) error {
+	return i.GroupDeleteContext(context.Background(), id)
+}
+
+// GroupDeleteContext is like GroupDelete but uses ctx for the request.
+func (i *IAMService) GroupDeleteContext(ctx context.Context, id string) error {
 	if id == "" {
 		return errIdentifierEmpty
 	}
-	req, err := i.client.NewRequest("DELETE", "iam", fmt.Sprintf("/v1.0/group/%s", id), nil)
+	req, err := i.client.NewRequestContext(ctx, "DELETE", "iam", fmt.Sprintf("/v1.0/group/%s", id), nil)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
 
 // GroupSetScopes set the scopes of a Group
 func (i *IAMService) GroupSetScopes(id string, scopes []string) error {
+	return i.GroupSetScopesContext(context.Background(), id, scopes)
+}
+
+// GroupSetScopesContext is like GroupSetScopes but uses ctx for the request.
+func (i *IAMService) GroupSetScopesContext(ctx context.Context, id string, scopes []string) error {
 	if id == "" {
 		return errIdentifierEmpty
 	}
-	req, err := i.client.NewRequest("PUT", "iam", fmt.Sprintf("/v1.0/group/%s/scopes", id), scopes)
+	req, err := i.client.NewRequestContext(ctx, "PUT", "iam", fmt.Sprintf("/v1.0/group/%s/scopes", id), scopes)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
 
 // GroupDeleteScope deletes a scope of a group
 func (i *IAMService) GroupDeleteScope(id string, scope string) error {
+	return i.GroupDeleteScopeContext(context.Background(), id, scope)
+}
+
+// GroupDeleteScopeContext is like GroupDeleteScope but uses ctx for the request.
+func (i *IAMService) GroupDeleteScopeContext(ctx context.Context, id string, scope string) error {
 	if id == "" {
 		return errIdentifierEmpty
 	}
-	req, err := i.client.NewRequest("DELETE", "iam", fmt.Sprintf("/v1.0/group/%s/scopes/%s", id, scope), nil)
+	req, err := i.client.NewRequestContext(ctx, "DELETE", "iam", fmt.Sprintf("/v1.0/group/%s/scopes/%s", id, scope), nil)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
@@ -1,6 +1,9 @@
 package corbel
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // IAMUser is the representation of an User object used by IAM
 type IAMUser struct {
@@ -23,60 +26,119 @@ type IAMUser struct {
 
 // UserAdd adds an IAMUser defined struct to the domain of the client
 func (i *IAMService) UserAdd(user *IAMUser) (string, error) {
-	req, err := i.client.NewRequest("POST", "iam", "/v1.0/user", user)
+	return i.UserAddContext(context.Background(), user)
+}
+
+// UserAddContext is like UserAdd but uses ctx for the request.
+func (i *IAMService) UserAddContext(ctx context.Context, user *IAMUser) (string, error) {
+	req, err := i.client.NewRequestContext(ctx, "POST", "iam", "/v1.0/user", user)
 	return returnErrorHTTPSimple(i.client, req, err, 201)
 }
 
+// UserAddCreated is like UserAdd but returns the parsed location of the
+// created user. If fetched is not nil the created user is also got into it.
+func (i *IAMService) UserAddCreated(user, fetched *IAMUser) (*Created, error) {
+	return i.UserAddCreatedContext(context.Background(), user, fetched)
+}
+
+// UserAddCreatedContext is like UserAddCreated but uses ctx for the requests.
+func (i *IAMService) UserAddCreatedContext(ctx context.Context, user, fetched *IAMUser) (*Created, error) {
+	location, err := i.UserAddContext(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	created, err := parseCreated(location)
+	if err != nil || fetched == nil {
+		return created, err
+	}
+	return created, i.UserGetContext(ctx, created.ID, fetched)
+}
+
 // UserExists checks if an user exists in the domain of the client
 func (i *IAMService) UserExists(username string) bool {
-	req, err := i.client.NewRequest("HEAD", "iam", fmt.Sprintf("/v1.0/username/%s", username), nil)
+	return i.UserExistsContext(context.Background(), username)
+}
+
+// UserExistsContext is like UserExists but uses ctx for the request.
+func (i *IAMService) UserExistsContext(ctx context.Context, username string) bool {
+	req, err := i.client.NewRequestContext(ctx, "HEAD", "iam", fmt.Sprintf("/v1.0/username/%s", username), nil)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 200)
 	return err == nil
 }
 
 // UserUpdate updates an user by using IAMUser
 func (i *IAMService) UserUpdate(id string, user *IAMUser) error {
+	return i.UserUpdateContext(context.Background(), id, user)
+}
+
+// UserUpdateContext is like UserUpdate but uses ctx for the request.
+func (i *IAMService) UserUpdateContext(ctx context.Context, id string, user *IAMUser) error {
 	if id == "" {
 		return errIdentifierEmpty
 	}
-	req, err := i.client.NewRequest("PUT", "iam", fmt.Sprintf("/v1.0/user/%s", id), user)
+	req, err := i.client.NewRequestContext(ctx, "PUT", "iam", fmt.Sprintf("/v1.0/user/%s", id), user)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
 
 //UserUpdateMe updates the user authenticated by the current token
 func (i *IAMService) UserUpdateMe(user *IAMUser) error {
-	return i.UserUpdate("me", user)
+	return i.UserUpdateMeContext(context.Background(), user)
+}
+
+// UserUpdateMeContext is like UserUpdateMe but uses ctx for the request.
+func (i *IAMService) UserUpdateMeContext(ctx context.Context, user *IAMUser) error {
+	return i.UserUpdateContext(ctx, "me", user)
 }
 
 // UserGet gets the desired IAMUuser from the domain by id
 func (i *IAMService) UserGet(id string, user *IAMUser) error {
+	return i.UserGetContext(context.Background(), id, user)
+}
+
+// UserGetContext is like UserGet but uses ctx for the request.
+func (i *IAMService) UserGetContext(ctx context.Context, id string, user *IAMUser) error {
 	if id == "" {
 		return errIdentifierEmpty
 	}
-	req, err := i.client.NewRequest("GET", "iam", fmt.Sprintf("/v1.0/user/%s", id), nil)
+	req, err := i.client.NewRequestContext(ctx, "GET", "iam", fmt.Sprintf("/v1.0/user/%s", id), nil)
 	_, err = returnErrorHTTPInterface(i.client, req, err, user, 200)
 	return err
 }
 
 // UserGetMe gets the user authenticated by the current token
 func (i *IAMService) UserGetMe(user *IAMUser) error {
-	return i.UserGet("me", user)
+	return i.UserGetMeContext(context.Background(), user)
+}
+
+// UserGetMeContext is like UserGetMe but uses ctx for the request.
+func (i *IAMService) UserGetMeContext(ctx context.Context, user *IAMUser) error {
+	return i.UserGetContext(ctx, "me", user)
 }
 
 // UserDelete deletes the desired user from IAM by id
 func (i *IAMService) UserDelete(id string) error {
+	return i.UserDeleteContext(context.Background(), id)
+}
+
+// UserDeleteContext is like UserDelete but uses ctx for the request.
+func (i *IAMService) UserDeleteContext(ctx context.Context, id string) error {
 	if id == "" {
 		return errIdentifierEmpty
 	}
-	req, err := i.client.NewRequest("DELETE", "iam", fmt.Sprintf("/v1.0/user/%s", id), nil)
+	req, err := i.client.NewRequestContext(ctx, "DELETE", "iam", fmt.Sprintf("/v1.0/user/%s", id), nil)
 	_, err = returnErrorHTTPSimple(i.client, req, err, 204)
 	return err
 }
 
 //UserDeleteMe deletes the user authenticated by the current token
 func (i *IAMService) UserDeleteMe() error {
-	return i.UserDelete("me")
+	return i.UserDeleteMeContext(context.Background())
+}
+
+// UserDeleteMeContext is like UserDeleteMe but uses ctx for the request.
+func (i *IAMService) UserDeleteMeContext(ctx context.Context) error {
+	return i.UserDeleteContext(ctx, "me")
 }
 
 // UserSearch gets the desired objects in base of a search query
@@ -86,11 +148,16 @@ func (i *IAMService) UserSearch() *Search {
 
 // UserByUsername allow to find an user based on its username
 func (i *IAMService) UserByUsername(username string) (*IAMUser, error) {
+	return i.UserByUsernameContext(context.Background(), username)
+}
+
+// UserByUsernameContext is like UserByUsername but uses ctx for the request.
+func (i *IAMService) UserByUsernameContext(ctx context.Context, username string) (*IAMUser, error) {
 	search := i.UserSearch()
 	search.Query.Eq["username"] = username
 
 	var arrUsers []*IAMUser
-	err := search.Page(0, &arrUsers)
+	err := search.PageContext(ctx, 0, &arrUsers)
 	if err != nil {
 		return nil, err
 	}
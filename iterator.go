@@ -0,0 +1,227 @@
+package corbel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// Iterator walks all the results of a Search fetching the pages lazily.
+//
+//	it := search.Iterator()
+//	for it.Next() {
+//		var item ResourceForTest
+//		if err := it.Scan(&item); err != nil {
+//			return err
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator struct {
+	search  *Search
+	ctx     context.Context
+	page    int
+	items   []json.RawMessage
+	current json.RawMessage
+	last    bool
+	err     error
+
+	// keyset pagination
+	keys  []string
+	after []interface{}
+}
+
+// Iterator returns an Iterator over all the results of the search
+func (s *Search) Iterator() *Iterator {
+	return s.IteratorContext(context.Background())
+}
+
+// IteratorContext is like Iterator but uses ctx for the requests.
+func (s *Search) IteratorContext(ctx context.Context) *Iterator {
+	return &Iterator{
+		search: s,
+		ctx:    ctx,
+	}
+}
+
+// KeysetIterator returns an Iterator over all the results of the search that
+// pages by keys instead of by page number. Every page asks for the items whose
+// keys are greater than the ones of the last item seen, so the scan is
+// complete and without duplicates even if items are inserted or deleted
+// meanwhile. The keys together must be unique, so keys that can be repeated,
+// like _createdAt, must be followed by a unique one, like id:
+//
+//	it := search.KeysetIterator("_createdAt", "id")
+//
+// Otherwise the items sharing the key of the last item of a page are lost.
+// Without keys it pages by id. The search is sorted by the keys in ascending
+// order, so it must not have any other sort.
+func (s *Search) KeysetIterator(keys ...string) *Iterator {
+	return s.KeysetIteratorContext(context.Background(), keys...)
+}
+
+// KeysetIteratorContext is like KeysetIterator but uses ctx for the requests.
+func (s *Search) KeysetIteratorContext(ctx context.Context, keys ...string) *Iterator {
+	if len(keys) == 0 {
+		keys = []string{"id"}
+	}
+	it := s.IteratorContext(ctx)
+	it.keys = keys
+	return it
+}
+
+// Next advances the iterator to the next result, fetching the next page when
+// needed. It returns false when there are no more results or an error happened.
+func (it *Iterator) Next() bool {
+	it.current = nil
+	if it.err != nil {
+		return false
+	}
+	if len(it.items) == 0 {
+		if it.last {
+			return false
+		}
+		if it.err = it.fetch(); it.err != nil || len(it.items) == 0 {
+			return false
+		}
+	}
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// fetch requests the next page of the search. A page shorter than PageSize
+// is the last one.
+func (it *Iterator) fetch() error {
+	search, page := it.search, it.page
+	if len(it.keys) > 0 {
+		var err error
+		if search, err = it.keysetSearch(); err != nil {
+			return err
+		}
+		page = 0
+	}
+
+	var items []json.RawMessage
+	if err := search.PageContext(it.ctx, page, &items); err != nil {
+		return err
+	}
+	it.page++
+	it.items = items
+	it.last = len(items) == 0 || (it.search.PageSize > 0 && len(items) < it.search.PageSize)
+
+	if len(it.keys) > 0 && len(items) > 0 {
+		after := make([]interface{}, len(it.keys))
+		for i, key := range it.keys {
+			value, err := keyValue(items[len(items)-1], key)
+			if err != nil {
+				return err
+			}
+			after[i] = value
+		}
+		it.after = after
+	}
+	return nil
+}
+
+// keysetSearch returns a copy of the search sorted by the keys and only with
+// the items after the last one seen
+func (it *Iterator) keysetSearch() (*Search, error) {
+	keySorts := make([]SortKey, len(it.keys))
+	for i, key := range it.keys {
+		keySorts[i] = SortKey{key, SortAsc}
+	}
+	if sorts := it.search.Sort.sortKeys(); len(sorts) > 0 {
+		if len(sorts) != len(keySorts) {
+			return nil, errKeysetSort
+		}
+		for i := range sorts {
+			if sorts[i] != keySorts[i] {
+				return nil, errKeysetSort
+			}
+		}
+	}
+
+	search := *it.search
+	search.Sort = &sort{keys: keySorts}
+	if it.after != nil {
+		search.conditions = append(search.conditions[:len(search.conditions):len(search.conditions)], it.afterCondition())
+	}
+	return &search, nil
+}
+
+// afterCondition matches the items whose keys are greater than the ones of
+// the last item seen: the first key is greater, or it's equal and the second
+// one is greater, and so on.
+func (it *Iterator) afterCondition() Condition {
+	if len(it.keys) == 1 {
+		return Gt(it.keys[0], it.after[0])
+	}
+	groups := make([]Condition, len(it.keys))
+	for i, key := range it.keys {
+		group := make([]Condition, 0, i+1)
+		for j := 0; j < i; j++ {
+			group = append(group, Eq(it.keys[j], it.after[j]))
+		}
+		groups[i] = And(append(group, Gt(key, it.after[i]))...)
+	}
+	return Or(groups...)
+}
+
+// keyValue returns the value of key in item. Nested fields are separated by
+// dots.
+func keyValue(item json.RawMessage, key string) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(item))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	for _, field := range strings.Split(key, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errKeysetMissingKey
+		}
+		if value, ok = object[field]; !ok {
+			return nil, errKeysetMissingKey
+		}
+	}
+	return value, nil
+}
+
+// Scan decodes the current result into v
+func (it *Iterator) Scan(v interface{}) error {
+	if it.current == nil {
+		return errIteratorNoCurrent
+	}
+	return json.Unmarshal(it.current, v)
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// All fills the slice pointed by result with all the results of the search
+func (s *Search) All(result interface{}) error {
+	return s.AllContext(context.Background(), result)
+}
+
+// AllContext is like All but uses ctx for the requests.
+func (s *Search) AllContext(ctx context.Context, result interface{}) error {
+	var items [][]byte
+	it := s.IteratorContext(ctx)
+	for it.Next() {
+		items = append(items, it.current)
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	buf.Write(bytes.Join(items, []byte(",")))
+	buf.WriteByte(']')
+	return json.Unmarshal(buf.Bytes(), result)
+}
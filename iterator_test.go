@@ -0,0 +1,281 @@
+package corbel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+type iteratorTestItem struct {
+	ID int `json:"id"`
+}
+
+// newIteratorTestClient returns a client whose server has total items with
+// consecutive ids and records the pages requested
+func newIteratorTestClient(t *testing.T, total int, pages *[]int) *Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("api:page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("api:pageSize"))
+		*pages = append(*pages, page)
+
+		items := []iteratorTestItem{}
+		for id := page * pageSize; id < total && id < (page+1)*pageSize; id++ {
+			items = append(items, iteratorTestItem{ID: id})
+		}
+		json.NewEncoder(w).Encode(items)
+	}))
+}
+
+func TestSearchIterator(t *testing.T) {
+	tests := []struct {
+		total int
+		pages int
+	}{
+		{0, 1},
+		{3, 1},
+		{5, 2},
+		{7, 2},
+	}
+
+	for _, test := range tests {
+		var pages []int
+		client := newIteratorTestClient(t, test.total, &pages)
+		search := client.Resources.SearchCollection("test:Collection")
+		search.PageSize = 5
+
+		it := search.Iterator()
+		count := 0
+		for it.Next() {
+			var item iteratorTestItem
+			if err := it.Scan(&item); err != nil {
+				t.Fatalf("Scan returned error: %v", err)
+			}
+			if got, want := item.ID, count; got != want {
+				t.Errorf("Error in item scanned. Got: %v, Want: %v", got, want)
+			}
+			count++
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("Iterator returned error: %v", err)
+		}
+		if got, want := count, test.total; got != want {
+			t.Errorf("Error in items iterated. Got: %v, Want: %v", got, want)
+		}
+		if got, want := len(pages), test.pages; got != want {
+			t.Errorf("Error in pages requested for %d items. Got: %v, Want: %v", test.total, got, want)
+		}
+		if it.Next() {
+			t.Error("Next must return false after the last result")
+		}
+	}
+}
+
+func TestSearchIteratorError(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	it := client.Resources.SearchCollection("test:Collection").Iterator()
+	if it.Next() {
+		t.Error("Next must return false if the page cannot be fetched")
+	}
+	if it.Err() == nil {
+		t.Error("Err must return the error fetching the page")
+	}
+	if err := it.Scan(&iteratorTestItem{}); err != errIteratorNoCurrent {
+		t.Errorf("Scan without current result must fail. Got: %v", err)
+	}
+}
+
+func TestSearchAll(t *testing.T) {
+	var pages []int
+	client := newIteratorTestClient(t, 23, &pages)
+	search := client.IAM.UserSearch()
+
+	var items []iteratorTestItem
+	if err := search.All(&items); err != nil {
+		t.Fatalf("All returned error: %v", err)
+	}
+	if got, want := len(items), 23; got != want {
+		t.Fatalf("Error in items returned. Got: %v, Want: %v", got, want)
+	}
+	for i, item := range items {
+		if got, want := item.ID, i; got != want {
+			t.Errorf("Error in item %d. Got: %v, Want: %v", i, got, want)
+		}
+	}
+	if got, want := len(pages), 3; got != want {
+		t.Errorf("Error in pages requested. Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestSearchKeysetIterator(t *testing.T) {
+	ids := []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120}
+	var requests int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got, want := r.URL.Query().Get("api:sort"), `{"id":"asc"}`; got != want {
+			t.Errorf("Error in api:sort. Got: %v, Want: %v", got, want)
+		}
+		if got, want := r.URL.Query().Get("api:page"), ""; got != want {
+			t.Errorf("Error in api:page. Got: %v, Want: %v", got, want)
+		}
+
+		after := -1
+		var query []map[string]map[string]int
+		if apiQuery := r.URL.Query().Get("api:query"); apiQuery != "" {
+			if err := json.Unmarshal([]byte(apiQuery), &query); err != nil {
+				t.Fatalf("Error decoding api:query %v: %v", apiQuery, err)
+			}
+		}
+		for _, condition := range query {
+			if gt, ok := condition["$gt"]; ok {
+				after = gt["id"]
+			}
+		}
+
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("api:pageSize"))
+		items := []iteratorTestItem{}
+		for _, id := range ids {
+			if id > after && len(items) < pageSize {
+				items = append(items, iteratorTestItem{ID: id})
+			}
+		}
+		json.NewEncoder(w).Encode(items)
+
+		// items inserted while scanning
+		if requests == 1 {
+			ids = append([]int{5}, append(ids, 125)...)
+		}
+	}))
+
+	search := client.Resources.SearchCollection("test:Collection")
+	search.PageSize = 5
+	it := search.KeysetIterator("id")
+
+	var got []int
+	for it.Next() {
+		var item iteratorTestItem
+		if err := it.Scan(&item); err != nil {
+			t.Fatalf("Scan returned error: %v", err)
+		}
+		got = append(got, item.ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterator returned error: %v", err)
+	}
+
+	want := []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120, 125}
+	if len(got) != len(want) {
+		t.Fatalf("Error in items iterated. Got: %v, Want: %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Error in items iterated. Got: %v, Want: %v", got, want)
+		}
+	}
+	if got, want := search.apiQuery(), ``; got != want {
+		t.Errorf("KeysetIterator must not modify the search. Got: %v, Want: %v", got, want)
+	}
+}
+
+// keysetTestMatch reports if item matches the api:query conditions used by
+// the keyset iterator: $gt, $eq and $or of groups of them
+func keysetTestMatch(item map[string]float64, conditions []map[string]json.RawMessage) bool {
+	for _, condition := range conditions {
+		for operator, value := range condition {
+			var match bool
+			switch operator {
+			case "$or":
+				var groups [][]map[string]json.RawMessage
+				json.Unmarshal(value, &groups)
+				for _, group := range groups {
+					match = match || keysetTestMatch(item, group)
+				}
+			default:
+				var fields map[string]float64
+				json.Unmarshal(value, &fields)
+				for field, v := range fields {
+					match = (operator == "$gt" && item[field] > v) || (operator == "$eq" && item[field] == v)
+				}
+			}
+			if !match {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestSearchKeysetIteratorCompoundKey(t *testing.T) {
+	items := []map[string]float64{
+		{"_createdAt": 1, "id": 1},
+		{"_createdAt": 2, "id": 2},
+		{"_createdAt": 2, "id": 3},
+		{"_createdAt": 2, "id": 4},
+		{"_createdAt": 3, "id": 5},
+	}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("api:sort"), `{"_createdAt":"asc","id":"asc"}`; got != want {
+			t.Errorf("Error in api:sort. Got: %v, Want: %v", got, want)
+		}
+		var query []map[string]json.RawMessage
+		if apiQuery := r.URL.Query().Get("api:query"); apiQuery != "" {
+			if err := json.Unmarshal([]byte(apiQuery), &query); err != nil {
+				t.Fatalf("Error decoding api:query %v: %v", apiQuery, err)
+			}
+		}
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("api:pageSize"))
+		page := []map[string]float64{}
+		for _, item := range items {
+			if keysetTestMatch(item, query) && len(page) < pageSize {
+				page = append(page, item)
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+
+	search := client.Resources.SearchCollection("test:Collection")
+	search.PageSize = 2
+	it := search.KeysetIterator("_createdAt", "id")
+
+	var got []int
+	for it.Next() {
+		var item iteratorTestItem
+		if err := it.Scan(&item); err != nil {
+			t.Fatalf("Scan returned error: %v", err)
+		}
+		got = append(got, item.ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterator returned error: %v", err)
+	}
+	if got, want := fmt.Sprint(got), "[1 2 3 4 5]"; got != want {
+		t.Errorf("Error in items iterated with repeated keys. Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestSearchKeysetIteratorErrors(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name":"test"}]`))
+	}))
+
+	search := client.Resources.SearchCollection("test:Collection").OrderBy(SortKey{"name", SortAsc})
+	it := search.KeysetIterator("id")
+	if it.Next() {
+		t.Error("Next must return false if the search is sorted by another field")
+	}
+	if got, want := it.Err(), errKeysetSort; got != want {
+		t.Errorf("Error in keyset iterator sorted by other field. Got: %v, Want: %v", got, want)
+	}
+
+	it = client.Resources.SearchCollection("test:Collection").KeysetIterator("meta.id")
+	if it.Next() {
+		t.Error("Next must return false if the key is not found")
+	}
+	if got, want := it.Err(), errKeysetMissingKey; got != want {
+		t.Errorf("Error in keyset iterator without key. Got: %v, Want: %v", got, want)
+	}
+}
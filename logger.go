@@ -0,0 +1,187 @@
+package corbel
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	stdsort "sort" // the package has its own sort type
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Level is the severity of a log message.
+type Level int
+
+// Log levels used by the client.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Fields are the structured values attached to a log message.
+type Fields map[string]interface{}
+
+// Logger is the interface used by the client to log its activity. Messages
+// and fields are redacted before reaching the Logger, so tokens, secrets and
+// passwords are never logged.
+type Logger interface {
+	Log(level Level, msg string, fields Fields)
+}
+
+// NewLogrusLogger returns a Logger that writes to a logrus logger.
+func NewLogrusLogger(l *logrus.Logger) Logger {
+	return logrusLogger{l}
+}
+
+type logrusLogger struct {
+	logger *logrus.Logger
+}
+
+func (l logrusLogger) Log(level Level, msg string, fields Fields) {
+	entry := l.logger.WithFields(logrus.Fields(fields))
+	switch level {
+	case LevelDebug:
+		entry.Debug(msg)
+	case LevelInfo:
+		entry.Info(msg)
+	case LevelWarn:
+		entry.Warn(msg)
+	default:
+		entry.Error(msg)
+	}
+}
+
+// NewStdLogger returns a Logger that writes to a logger of the standard log
+// package, with the fields as sorted key=value pairs.
+func NewStdLogger(l *log.Logger) Logger {
+	return stdLogger{l}
+}
+
+type stdLogger struct {
+	logger *log.Logger
+}
+
+func (l stdLogger) Log(level Level, msg string, fields Fields) {
+	keys := sortedKeys(fields)
+
+	line := fmt.Sprintf("[%s] %s", strings.ToUpper(level.String()), msg)
+	for _, key := range keys {
+		line += fmt.Sprintf(" %s=%v", key, fields[key])
+	}
+	l.logger.Print(line)
+}
+
+// NewSlogLogger returns a Logger that writes to a log/slog structured logger.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Log(level Level, msg string, fields Fields) {
+	keys := sortedKeys(fields)
+
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+
+	slogLevel := slog.LevelError
+	switch level {
+	case LevelDebug:
+		slogLevel = slog.LevelDebug
+	case LevelInfo:
+		slogLevel = slog.LevelInfo
+	case LevelWarn:
+		slogLevel = slog.LevelWarn
+	}
+	l.logger.LogAttrs(context.Background(), slogLevel, msg, attrs...)
+}
+
+// sortedKeys returns the keys of the fields in order
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	stdsort.Strings(keys)
+	return keys
+}
+
+// parseLevel converts a log level name to a Level
+func parseLevel(name string) (Level, error) {
+	level, err := logrus.ParseLevel(name)
+	if err != nil {
+		return 0, errInvalidLogLevel
+	}
+	switch level {
+	case logrus.DebugLevel:
+		return LevelDebug, nil
+	case logrus.InfoLevel:
+		return LevelInfo, nil
+	case logrus.WarnLevel:
+		return LevelWarn, nil
+	default:
+		return LevelError, nil
+	}
+}
+
+// logger is the logger used inside the client. It drops the messages under
+// its level and redacts the rest before sending them to the Logger.
+type logger struct {
+	Logger
+	level Level
+}
+
+func (l *logger) log(level Level, msg string, fields Fields) {
+	if level < l.level {
+		return
+	}
+	l.Logger.Log(level, redact(msg), redactFields(fields))
+}
+
+func (l *logger) Debug(msg string) {
+	l.log(LevelDebug, msg, nil)
+}
+
+func (l *logger) Debugf(format string, args ...interface{}) {
+	l.log(LevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *logger) Warnf(format string, args ...interface{}) {
+	l.log(LevelWarn, fmt.Sprintf(format, args...), nil)
+}
+
+// WithFields returns an entry to log a message with fields
+func (l *logger) WithFields(fields Fields) *logEntry {
+	return &logEntry{logger: l, fields: fields}
+}
+
+type logEntry struct {
+	logger *logger
+	fields Fields
+}
+
+func (e *logEntry) Debug(msg string) {
+	e.logger.log(LevelDebug, msg, e.fields)
+}
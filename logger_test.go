@@ -0,0 +1,79 @@
+package corbel
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// recordLogger is a Logger that keeps the logged messages
+type recordLogger struct {
+	lines []string
+}
+
+func (r *recordLogger) Log(level Level, msg string, fields Fields) {
+	var buf bytes.Buffer
+	NewStdLogger(log.New(&buf, "", 0)).Log(level, msg, fields)
+	r.lines = append(r.lines, strings.TrimSpace(buf.String()))
+}
+
+func TestLoggerLevel(t *testing.T) {
+	record := &recordLogger{}
+	l := &logger{Logger: record, level: LevelWarn}
+
+	l.Debug("debug message")
+	l.Warnf("warning %s", "message")
+	if got, want := len(record.lines), 1; got != want {
+		t.Fatalf("Logged messages are %v, but want %v", got, want)
+	}
+	if got, want := record.lines[0], "[WARNING] warning message"; got != want {
+		t.Errorf("Logged message is %v, but want %v", got, want)
+	}
+}
+
+func TestLoggerAdapters(t *testing.T) {
+	var buf bytes.Buffer
+	NewStdLogger(log.New(&buf, "", 0)).Log(LevelInfo, "message", Fields{"b": 2, "a": 1})
+	if got, want := buf.String(), "[INFO] message a=1 b=2\n"; got != want {
+		t.Errorf("Std logger line is %q, but want %q", got, want)
+	}
+
+	buf.Reset()
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	NewSlogLogger(slog.New(handler)).Log(LevelDebug, "message", Fields{"key": "value"})
+	if got, want := buf.String(), "level=DEBUG msg=message key=value\n"; got != want {
+		t.Errorf("Slog logger line is %q, but want %q", got, want)
+	}
+}
+
+func TestLoggerClientRedaction(t *testing.T) {
+	record := &recordLogger{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"accessToken":"secretAccessToken","expiresAt":4102444800000,"refreshToken":"secretRefreshToken"}`))
+	}))
+	client.logger = &logger{Logger: record, level: LevelDebug}
+
+	if err := client.IAM.OauthTokenBasicAuth("someUser", "somePassword"); err != nil {
+		t.Fatalf("OauthTokenBasicAuth must not fail. Got: %v", err)
+	}
+	user := &IAMUser{Username: "someUser", Password: "somePassword"}
+	client.IAM.UserAdd(user)
+
+	logs := strings.Join(record.lines, "\n")
+	for _, secret := range []string{"someSecret", "somePassword", "secretAccessToken", "secretRefreshToken", "eyJ"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("Logs must not contain %q. Got:\n%s", secret, logs)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package corbel
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Client created with New.
+type Option func(*clientOptions) error
+
+// clientOptions are the values collected from the options before validating
+// them and building the Client.
+type clientOptions struct {
+	httpClient       *http.Client
+	endpoints        map[string]string
+	clientID         string
+	clientName       string
+	clientSecret     string
+	scopes           string
+	domain           string
+	signingMethod    string
+	signingMethodSet bool
+	rsaPrivateKey    string
+	tokenTTL         time.Duration
+	tokenRefreshSkew time.Duration
+	retryPolicy      *RetryPolicy
+	cache            CacheStore
+	logger           Logger
+	logLevel         string
+	logLevelSet      bool
+}
+
+// WithHTTPClient sets the HTTP client used to communicate with the platform.
+// A nil httpClient keeps http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) error {
+		if httpClient != nil {
+			o.httpClient = httpClient
+		}
+		return nil
+	}
+}
+
+// WithEndpoints sets the uri of each endpoint of the platform.
+// Empty endpoints keep the production ones.
+func WithEndpoints(endpoints map[string]string) Option {
+	return func(o *clientOptions) error {
+		if len(endpoints) > 0 {
+			o.endpoints = endpoints
+		}
+		return nil
+	}
+}
+
+// WithCredentials sets the client ID and secret of the application defined
+// on the platform. For RSA signing methods clientSecret is the PEM encoded
+// private key.
+func WithCredentials(clientID, clientSecret string) Option {
+	return func(o *clientOptions) error {
+		o.clientID = clientID
+		o.clientSecret = clientSecret
+		return nil
+	}
+}
+
+// WithClientName sets the name that match the client ID.
+func WithClientName(clientName string) Option {
+	return func(o *clientOptions) error {
+		o.clientName = clientName
+		return nil
+	}
+}
+
+// WithScopes sets the scopes the client asks for. Every scope can also be
+// an string of several scopes delimited by spaces.
+func WithScopes(scopes ...string) Option {
+	return func(o *clientOptions) error {
+		var fields []string
+		for _, scope := range scopes {
+			fields = append(fields, strings.Fields(scope)...)
+		}
+		o.scopes = strings.Join(fields, " ")
+		return nil
+	}
+}
+
+// WithDomain sets the domain where to make the operations.
+func WithDomain(domain string) Option {
+	return func(o *clientOptions) error {
+		o.domain = domain
+		return nil
+	}
+}
+
+// WithSigningMethod sets the JWT signing method configured for the client
+// on the platform.
+func WithSigningMethod(method string) Option {
+	return func(o *clientOptions) error {
+		o.signingMethod = method
+		o.signingMethodSet = true
+		return nil
+	}
+}
+
+// WithRSAPrivateKey sets the PEM encoded private key used to sign the client
+// assertions instead of the client secret. The signing method is RS256 unless
+// another RSA one is set with WithSigningMethod.
+func WithRSAPrivateKey(pem []byte) Option {
+	return func(o *clientOptions) error {
+		o.rsaPrivateKey = string(pem)
+		return nil
+	}
+}
+
+// WithRSAPrivateKeyFile is like WithRSAPrivateKey but reads the key from path.
+func WithRSAPrivateKeyFile(path string) Option {
+	return func(o *clientOptions) error {
+		pem, err := RSAPrivateKeyFromFile(path)
+		if err != nil {
+			return err
+		}
+		return WithRSAPrivateKey([]byte(pem))(o)
+	}
+}
+
+// WithTokenTTL sets how long the requested tokens must be valid. It must be
+// between 1 and 3600 seconds.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(o *clientOptions) error {
+		o.tokenTTL = ttl
+		return nil
+	}
+}
+
+// WithTokenRefreshSkew sets the Client TokenRefreshSkew.
+func WithTokenRefreshSkew(skew time.Duration) Option {
+	return func(o *clientOptions) error {
+		o.tokenRefreshSkew = skew
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the Client RetryPolicy.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(o *clientOptions) error {
+		o.retryPolicy = policy
+		return nil
+	}
+}
+
+// WithCache caches the responses of GET requests in store. A nil store uses
+// an in memory LRU cache of DefaultCacheSize responses.
+func WithCache(store CacheStore) Option {
+	return func(o *clientOptions) error {
+		if store == nil {
+			store = NewLRUCache(DefaultCacheSize)
+		}
+		o.cache = store
+		return nil
+	}
+}
+
+// WithLogger sets the logger used by the client. Every message is sent to it
+// unless WithLogLevel is also used. Adapters are available for logrus, the
+// standard log package and log/slog.
+func WithLogger(logger Logger) Option {
+	return func(o *clientOptions) error {
+		o.logger = logger
+		return nil
+	}
+}
+
+// WithLogLevel sets the minimum level of the messages logged.
+func WithLogLevel(level string) Option {
+	return func(o *clientOptions) error {
+		o.logLevel = level
+		o.logLevelSet = true
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package corbel
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestOptionsNew(t *testing.T) {
+	httpClient := &http.Client{}
+	endpoints := map[string]string{"iam": "https://iam-qa.bqws.io", "resources": "https://resources-qa.bqws.io"}
+
+	client, err := New(
+		WithHTTPClient(httpClient),
+		WithEndpoints(endpoints),
+		WithCredentials("someID", "someSecret"),
+		WithClientName("someName"),
+		WithScopes("scope1 scope2", "scope3"),
+		WithDomain("someDomain"),
+		WithTokenTTL(300*time.Second),
+		WithTokenRefreshSkew(time.Second),
+		WithRetryPolicy(DefaultRetryPolicy()),
+		WithCache(nil),
+	)
+	if err != nil {
+		t.Fatalf("New must not fail. Got: %v", err)
+	}
+
+	if got, want := client.httpClient, httpClient; got != want {
+		t.Errorf("New HTTPClient is %v, but want %v", got, want)
+	}
+	if got, want := client.URLFor("iam", "/v1.0/auth/token"), "https://iam-qa.bqws.io/v1.0/auth/token"; got != want {
+		t.Errorf("New urlFor url is %v, but want %v", got, want)
+	}
+	if got, want := client.ClientID, "someID"; got != want {
+		t.Errorf("New ClientID is %v, but want %v", got, want)
+	}
+	if got, want := client.ClientSecret, "someSecret"; got != want {
+		t.Errorf("New ClientSecret is %v, but want %v", got, want)
+	}
+	if got, want := client.ClientName, "someName"; got != want {
+		t.Errorf("New ClientName is %v, but want %v", got, want)
+	}
+	if got, want := client.ClientScopes, "scope1 scope2 scope3"; got != want {
+		t.Errorf("New ClientScopes is %v, but want %v", got, want)
+	}
+	if got, want := client.ClientDomain, "someDomain"; got != want {
+		t.Errorf("New ClientDomain is %v, but want %v", got, want)
+	}
+	if got, want := client.ClientJWTSigningMethod, "HS256"; got != want {
+		t.Errorf("New ClientJWTSigningMethod is %v, but want %v", got, want)
+	}
+	if got, want := client.TokenExpirationTime, uint64(300000); got != want {
+		t.Errorf("New TokenExpirationTime is %v, but want %v", got, want)
+	}
+	if got, want := client.TokenRefreshSkew, time.Second; got != want {
+		t.Errorf("New TokenRefreshSkew is %v, but want %v", got, want)
+	}
+	if client.RetryPolicy == nil {
+		t.Errorf("New RetryPolicy must be set")
+	}
+	if client.Cache == nil {
+		t.Errorf("New Cache must be set")
+	}
+	if got, want := client.LogLevel, "info"; got != want {
+		t.Errorf("New LogLevel is %v, but want %v", got, want)
+	}
+}
+
+func TestOptionsValidation(t *testing.T) {
+	if _, err := New(); err != errMissingClientParams {
+		t.Errorf("New must fail without credentials. Got: %v", err)
+	}
+	if _, err := New(WithCredentials("someID", "someSecret"), WithSigningMethod("none")); err != errInvalidJWTSigningMethod {
+		t.Errorf("New must fail with an invalid signing method. Got: %v", err)
+	}
+	if _, err := New(WithCredentials("someID", "someSecret"), WithTokenTTL(time.Hour+time.Second)); err != errInvalidTokenExpirationTime {
+		t.Errorf("New must fail with a token TTL over 3600 seconds. Got: %v", err)
+	}
+	if _, err := New(WithCredentials("someID", "someSecret"), WithTokenTTL(time.Millisecond)); err != errInvalidTokenExpirationTime {
+		t.Errorf("New must fail with a token TTL under 1 second. Got: %v", err)
+	}
+	if _, err := New(WithCredentials("someID", "someSecret"), WithLogLevel("verbose")); err != errInvalidLogLevel {
+		t.Errorf("New must fail with an invalid log level. Got: %v", err)
+	}
+	if _, err := New(WithCredentials("someID", "someSecret"), WithSigningMethod("RS256")); err != errInvalidRSAPrivateKey {
+		t.Errorf("New must fail with an invalid RSA key. Got: %v", err)
+	}
+}
+
+func TestOptionsRSAPrivateKey(t *testing.T) {
+	_, keyPEM := newTestRSAKey(t)
+
+	client, err := New(WithCredentials("someID", ""), WithRSAPrivateKey([]byte(keyPEM)))
+	if err != nil {
+		t.Fatalf("New must not fail with an RSA key. Got: %v", err)
+	}
+	if got, want := client.ClientJWTSigningMethod, "RS256"; got != want {
+		t.Errorf("New ClientJWTSigningMethod is %v, but want %v", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "client.pem")
+	if err = ioutil.WriteFile(path, []byte(keyPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+	client, err = New(WithCredentials("someID", ""), WithSigningMethod("RS512"), WithRSAPrivateKeyFile(path))
+	if err != nil {
+		t.Fatalf("New must not fail with an RSA key file. Got: %v", err)
+	}
+	if got, want := client.ClientJWTSigningMethod, "RS512"; got != want {
+		t.Errorf("New ClientJWTSigningMethod is %v, but want %v", got, want)
+	}
+
+	if _, err = New(WithCredentials("someID", ""), WithRSAPrivateKeyFile(filepath.Join(t.TempDir(), "missing.pem"))); err == nil {
+		t.Errorf("New must fail if the RSA key file does not exist")
+	}
+	key := WithRSAPrivateKey([]byte(keyPEM))
+	for _, options := range [][]Option{
+		{key, WithCredentials("someID", "")},
+		{WithCredentials("someID", "someSecret"), key},
+		{key, WithCredentials("someID", "someSecret")},
+		{key, WithSigningMethod("RS384"), WithCredentials("someID", "")},
+		{WithSigningMethod("RS384"), key, WithCredentials("someID", "")},
+	} {
+		client, err := New(options...)
+		if err != nil {
+			t.Errorf("New must not fail with an RSA key in any order. Got: %v", err)
+			continue
+		}
+		if got, want := client.ClientSecret, keyPEM; got != want {
+			t.Errorf("New ClientSecret must be the RSA key in any order. Got: %v", got)
+		}
+		if !isRSASigningMethod(client.ClientJWTSigningMethod) {
+			t.Errorf("New ClientJWTSigningMethod must be an RSA one in any order. Got: %v", client.ClientJWTSigningMethod)
+		}
+	}
+
+	for _, options := range [][]Option{
+		{WithCredentials("someID", ""), key, WithSigningMethod("HS256")},
+		{WithCredentials("someID", ""), WithSigningMethod("HS256"), key},
+	} {
+		if _, err := New(options...); err != errRSAKeyWithHMAC {
+			t.Errorf("New must fail with an RSA key and an HMAC signing method in any order. Got: %v", err)
+		}
+	}
+}
+
+func TestOptionsLogger(t *testing.T) {
+	logger := logrus.New()
+	logger.Level = logrus.WarnLevel
+
+	client, err := New(WithCredentials("someID", "someSecret"), WithLogger(NewLogrusLogger(logger)))
+	if err != nil {
+		t.Fatalf("New must not fail with a logger. Got: %v", err)
+	}
+	if got, want := client.LogLevel, "debug"; got != want {
+		t.Errorf("New LogLevel is %v, but want %v", got, want)
+	}
+
+	client, err = New(WithCredentials("someID", "someSecret"), WithLogger(NewLogrusLogger(logger)), WithLogLevel("warning"))
+	if err != nil {
+		t.Fatalf("New must not fail with a logger and level. Got: %v", err)
+	}
+	if got, want := client.logger.level, LevelWarn; got != want {
+		t.Errorf("Logger level is %v, but want %v", got, want)
+	}
+
+	client, err = New(WithCredentials("someID", "someSecret"), WithLogLevel("error"), WithLogger(NewLogrusLogger(logger)))
+	if err != nil {
+		t.Fatalf("New must not fail with a level and logger. Got: %v", err)
+	}
+	if got, want := client.logger.level, LevelError; got != want {
+		t.Errorf("Logger level set before WithLogger is %v, but want %v", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package corbel
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Condition is a condition of a search query built with Eq, Ne, Gt, Gte, Lt,
+// Lte, In, Nin, All, Like, Exists, Size, ElemMatch, Not, And and Or. Values
+// can be any JSON serializable type.
+type Condition struct {
+	operator   string
+	field      string
+	value      interface{}
+	conditions []Condition
+}
+
+// Eq matches the items where field is equal to value
+func Eq(field string, value interface{}) Condition {
+	return Condition{operator: "$eq", field: field, value: value}
+}
+
+// Ne matches the items where field is not equal to value
+func Ne(field string, value interface{}) Condition {
+	return Condition{operator: "$ne", field: field, value: value}
+}
+
+// Gt matches the items where field is greater than value
+func Gt(field string, value interface{}) Condition {
+	return Condition{operator: "$gt", field: field, value: value}
+}
+
+// Gte matches the items where field is greater than or equal to value
+func Gte(field string, value interface{}) Condition {
+	return Condition{operator: "$gte", field: field, value: value}
+}
+
+// Lt matches the items where field is less than value
+func Lt(field string, value interface{}) Condition {
+	return Condition{operator: "$lt", field: field, value: value}
+}
+
+// Lte matches the items where field is less than or equal to value
+func Lte(field string, value interface{}) Condition {
+	return Condition{operator: "$lte", field: field, value: value}
+}
+
+// In matches the items where field is one of values
+func In(field string, values ...interface{}) Condition {
+	return Condition{operator: "$in", field: field, value: values}
+}
+
+// Nin matches the items where field is none of values
+func Nin(field string, values ...interface{}) Condition {
+	return Condition{operator: "$nin", field: field, value: values}
+}
+
+// All matches the items where the array field contains all the values
+func All(field string, values ...interface{}) Condition {
+	return Condition{operator: "$all", field: field, value: values}
+}
+
+// Like matches the items where field matches the pattern
+func Like(field, pattern string) Condition {
+	return Condition{operator: "$like", field: field, value: pattern}
+}
+
+// Exists matches the items where field exists, or not if exists is false
+func Exists(field string, exists bool) Condition {
+	return Condition{operator: "$exists", field: field, value: exists}
+}
+
+// Size matches the items where the array field has size elements
+func Size(field string, size int) Condition {
+	return Condition{operator: "$size", field: field, value: size}
+}
+
+// ElemMatch matches the items where an element of the array field matches
+// all the conditions
+func ElemMatch(field string, conditions ...Condition) Condition {
+	return Condition{operator: "$elemMatch", field: field, conditions: conditions}
+}
+
+// Not matches the items that do not match the condition
+func Not(condition Condition) Condition {
+	return Condition{operator: "$not", conditions: []Condition{condition}}
+}
+
+// And matches the items that match all the conditions. It's only needed to
+// group conditions inside Or, since conditions are combined with and by default.
+func And(conditions ...Condition) Condition {
+	return Condition{operator: "$and", conditions: conditions}
+}
+
+// Or matches the items that match any of the conditions
+func Or(conditions ...Condition) Condition {
+	return Condition{operator: "$or", conditions: conditions}
+}
+
+// MarshalJSON returns the condition as used by api:query
+func (c Condition) MarshalJSON() ([]byte, error) {
+	switch c.operator {
+	case "$and":
+		return json.Marshal(Conditions(c.conditions).flatten())
+	case "$or":
+		groups := make([][]Condition, len(c.conditions))
+		for i, condition := range c.conditions {
+			groups[i] = Conditions{condition}.flatten()
+		}
+		return json.Marshal(map[string]interface{}{c.operator: groups})
+	case "$not":
+		return json.Marshal(map[string]interface{}{c.operator: c.conditions[0]})
+	case "$elemMatch":
+		return json.Marshal(map[string]interface{}{
+			c.operator: map[string]interface{}{c.field: Conditions(c.conditions).flatten()},
+		})
+	}
+	return json.Marshal(map[string]interface{}{
+		c.operator: map[string]interface{}{c.field: c.value},
+	})
+}
+
+// UnmarshalJSON reads a condition as returned by MarshalJSON. A list of
+// conditions is read as an And group.
+func (c *Condition) UnmarshalJSON(data []byte) error {
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		var conditions []Condition
+		if err := json.Unmarshal(data, &conditions); err != nil {
+			return err
+		}
+		*c = And(conditions...)
+		return nil
+	}
+
+	operator, value, err := singleKey(data)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(operator, "$") {
+		return errInvalidCondition
+	}
+	switch operator {
+	case "$or":
+		var groups []Condition
+		if err := json.Unmarshal(value, &groups); err != nil {
+			return err
+		}
+		*c = Or(groups...)
+		return nil
+	case "$not":
+		var condition Condition
+		if err := json.Unmarshal(value, &condition); err != nil {
+			return err
+		}
+		*c = Not(condition)
+		return nil
+	}
+
+	field, value, err := singleKey(value)
+	if err != nil {
+		return err
+	}
+	if operator == "$elemMatch" {
+		var conditions []Condition
+		if err := json.Unmarshal(value, &conditions); err != nil {
+			return err
+		}
+		*c = ElemMatch(field, conditions...)
+		return nil
+	}
+	var fieldValue interface{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fieldValue); err != nil {
+		return err
+	}
+	*c = Condition{operator: operator, field: field, value: fieldValue}
+	return nil
+}
+
+// singleKey returns the key and value of a JSON object with only one key
+func singleKey(data []byte) (string, json.RawMessage, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return "", nil, err
+	}
+	if len(object) != 1 {
+		return "", nil, errInvalidCondition
+	}
+	for key, value := range object {
+		return key, value, nil
+	}
+	return "", nil, errInvalidCondition
+}
+
+// Conditions is a list of conditions that must be all matched.
+type Conditions []Condition
+
+// flatten returns the conditions with the And groups expanded
+func (cs Conditions) flatten() []Condition {
+	flat := make([]Condition, 0, len(cs))
+	for _, condition := range cs {
+		if condition.operator == "$and" {
+			flat = append(flat, Conditions(condition.conditions).flatten()...)
+			continue
+		}
+		flat = append(flat, condition)
+	}
+	return flat
+}
+
+// String returns the conditions as the array of conditions used by api:query
+func (cs Conditions) String() string {
+	if len(cs) == 0 {
+		return ""
+	}
+	query, _ := json.Marshal(cs.flatten())
+	return string(query)
+}
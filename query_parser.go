@@ -0,0 +1,530 @@
+package corbel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QuerySyntaxError is returned when a textual query cannot be parsed. Column
+// is the position, starting at 1, of the character where the error was found.
+type QuerySyntaxError struct {
+	Query  string
+	Column int
+	Msg    string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("Search: Query syntax error at column %d: %s", e.Column, e.Msg)
+}
+
+// ParseConditions parses a textual query into conditions, like
+//
+//	status = "active" and age >= 18 and (tags in ["a", "b"] or not owner exists)
+//
+// Comparisons are written as field, operator and value. The operators are
+// =, !=, >, >=, <, <=, in, not in, all, like, not like, exists, not exists and
+// size. Values are written as in JSON: "strings", numbers, true, false, null
+// and [lists]. Comparisons are combined with and, or, not and parentheses.
+//
+// Fields are written as is, and keywords can be fields too, like size > 3,
+// except not when it's not followed by =, !=, >, >=, < or <=. Fields with
+// other characters or that would be ambiguous are written between backticks,
+// doubling the backticks inside them, like `first name` = "Ann" or `not` exists.
+func ParseConditions(query string) (Conditions, error) {
+	p, err := newQueryParser(query)
+	if err != nil {
+		return nil, err
+	}
+	conditions, err := p.parseConditions()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	return conditions, nil
+}
+
+// Parse parses a textual query, as accepted by ParseConditions, followed by an
+// optional "order by field [asc|desc], ..." clause, and adds its conditions
+// and sort to the search. The search is not modified if the query is not valid.
+func (s *Search) Parse(query string) error {
+	p, err := newQueryParser(query)
+	if err != nil {
+		return err
+	}
+
+	var conditions Conditions
+	if !p.peekOrderBy() {
+		if conditions, err = p.parseConditions(); err != nil {
+			return err
+		}
+	}
+
+	var keys []SortKey
+	if p.peekKeyword("order") {
+		p.next()
+		if err := p.expectKeyword("by"); err != nil {
+			return err
+		}
+		sorted := make(map[string]bool)
+		for _, key := range s.Sort.sortKeys() {
+			sorted[key.Field] = true
+		}
+		for {
+			t := p.peek()
+			field, err := p.parseField()
+			if err != nil {
+				return err
+			}
+			if sorted[field] {
+				return p.errorAt(t.pos, fmt.Sprintf("field %s is sorted more than once", t))
+			}
+			sorted[field] = true
+
+			key := SortKey{field, SortAsc}
+			if p.peekKeyword(SortDesc) {
+				key.Direction = SortDesc
+				p.next()
+			} else if p.peekKeyword(SortAsc) {
+				p.next()
+			}
+			keys = append(keys, key)
+
+			if !p.peek().is(queryTokenPunct, ",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expectEnd(); err != nil {
+		return err
+	}
+
+	s.Where(conditions...)
+	s.Sort.By(keys...)
+	return nil
+}
+
+type queryTokenKind int
+
+const (
+	queryTokenEnd queryTokenKind = iota
+	queryTokenIdent
+	queryTokenField
+	queryTokenString
+	queryTokenNumber
+	queryTokenOperator
+	queryTokenPunct
+)
+
+// queryToken is a token of a textual query. pos is its byte offset.
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+func (t queryToken) is(kind queryTokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+// isKeyword reports if the token is the keyword, ignoring case
+func (t queryToken) isKeyword(keyword string) bool {
+	return t.kind == queryTokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+func (t queryToken) String() string {
+	if t.kind == queryTokenEnd {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+// queryParser is a recursive descent parser of textual queries
+type queryParser struct {
+	query  string
+	tokens []queryToken
+	pos    int
+}
+
+func newQueryParser(query string) (*queryParser, error) {
+	p := &queryParser{query: query}
+	for i := 0; i < len(query); {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			continue
+		case r == '(' || r == ')' || r == '[' || r == ']' || r == ',':
+			i++
+			p.tokens = append(p.tokens, queryToken{queryTokenPunct, query[start:i], start})
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			i++
+			if i < len(query) && query[i] == '=' {
+				i++
+			}
+			operator := query[start:i]
+			if operator == "!" {
+				return nil, p.errorAt(start, "unexpected \"!\", expected \"!=\"")
+			}
+			p.tokens = append(p.tokens, queryToken{queryTokenOperator, operator, start})
+		case r == '`':
+			var field strings.Builder
+			for i++; i < len(query); i++ {
+				if query[i] == '`' {
+					if i+1 >= len(query) || query[i+1] != '`' {
+						break
+					}
+					i++
+				}
+				field.WriteByte(query[i])
+			}
+			if i >= len(query) {
+				return nil, p.errorAt(start, "unterminated field")
+			}
+			i++
+			if field.Len() == 0 {
+				return nil, p.errorAt(start, "empty field")
+			}
+			p.tokens = append(p.tokens, queryToken{queryTokenField, field.String(), start})
+		case r == '"':
+			i++
+			for i < len(query) && query[i] != '"' {
+				if query[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(query) {
+				return nil, p.errorAt(start, "unterminated string")
+			}
+			i++
+			if !json.Valid([]byte(query[start:i])) {
+				return nil, p.errorAt(start, fmt.Sprintf("invalid string %s", query[start:i]))
+			}
+			p.tokens = append(p.tokens, queryToken{queryTokenString, query[start:i], start})
+		case r == '-' || r == '.' || unicode.IsDigit(r):
+			i++
+			for i < len(query) && strings.ContainsRune("0123456789.eE+-", rune(query[i])) {
+				i++
+			}
+			if !json.Valid([]byte(query[start:i])) {
+				return nil, p.errorAt(start, fmt.Sprintf("invalid number %q", query[start:i]))
+			}
+			p.tokens = append(p.tokens, queryToken{queryTokenNumber, query[start:i], start})
+		case r == '_' || unicode.IsLetter(r):
+			i += size
+			for i < len(query) {
+				r, size := utf8.DecodeRuneInString(query[i:])
+				if r != '_' && r != '.' && r != ':' && r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			p.tokens = append(p.tokens, queryToken{queryTokenIdent, query[start:i], start})
+		default:
+			return nil, p.errorAt(start, fmt.Sprintf("unexpected character %q", r))
+		}
+	}
+	p.tokens = append(p.tokens, queryToken{queryTokenEnd, "", len(query)})
+	return p, nil
+}
+
+// errorAt returns a syntax error at the byte offset pos of the query
+func (p *queryParser) errorAt(pos int, msg string) error {
+	return &QuerySyntaxError{
+		Query:  p.query,
+		Column: utf8.RuneCountInString(p.query[:pos]) + 1,
+		Msg:    msg,
+	}
+}
+
+// errorExpected returns a syntax error at the current token
+func (p *queryParser) errorExpected(expected string) error {
+	t := p.peek()
+	return p.errorAt(t.pos, fmt.Sprintf("expected %s, got %s", expected, t))
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.kind != queryTokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+// peekAt returns the token n positions after the current one
+func (p *queryParser) peekAt(n int) queryToken {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *queryParser) peekKeyword(keyword string) bool {
+	return p.peek().isKeyword(keyword)
+}
+
+// peekOrderBy reports if the order by clause starts at the current token
+func (p *queryParser) peekOrderBy() bool {
+	return p.peekKeyword("order") && p.peekAt(1).isKeyword("by")
+}
+
+func (p *queryParser) expectKeyword(keyword string) error {
+	if !p.peekKeyword(keyword) {
+		return p.errorExpected(strconv.Quote(keyword))
+	}
+	p.next()
+	return nil
+}
+
+func (p *queryParser) expectPunct(punct string) error {
+	if !p.peek().is(queryTokenPunct, punct) {
+		return p.errorExpected(strconv.Quote(punct))
+	}
+	p.next()
+	return nil
+}
+
+func (p *queryParser) expectEnd() error {
+	if p.peek().kind != queryTokenEnd {
+		return p.errorExpected("end of query")
+	}
+	return nil
+}
+
+// parseConditions parses a whole expression as a list of conditions
+func (p *queryParser) parseConditions() (Conditions, error) {
+	condition, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	return Conditions{condition}.flatten(), nil
+}
+
+// parseOr parses and-expressions joined by or
+func (p *queryParser) parseOr() (Condition, error) {
+	condition, err := p.parseAnd()
+	if err != nil {
+		return Condition{}, err
+	}
+	conditions := []Condition{condition}
+	for p.peekKeyword("or") {
+		p.next()
+		if condition, err = p.parseAnd(); err != nil {
+			return Condition{}, err
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return Or(conditions...), nil
+}
+
+// parseAnd parses unary expressions joined by and
+func (p *queryParser) parseAnd() (Condition, error) {
+	condition, err := p.parseUnary()
+	if err != nil {
+		return Condition{}, err
+	}
+	conditions := []Condition{condition}
+	for p.peekKeyword("and") {
+		p.next()
+		if condition, err = p.parseUnary(); err != nil {
+			return Condition{}, err
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return And(conditions...), nil
+}
+
+// parseUnary parses a negation, a parenthesized expression or a comparison
+func (p *queryParser) parseUnary() (Condition, error) {
+	switch {
+	case p.peekKeyword("not") && p.peekAt(1).kind != queryTokenOperator:
+		p.next()
+		condition, err := p.parseUnary()
+		if err != nil {
+			return Condition{}, err
+		}
+		return Not(condition), nil
+	case p.peek().is(queryTokenPunct, "("):
+		p.next()
+		condition, err := p.parseOr()
+		if err != nil {
+			return Condition{}, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return Condition{}, err
+		}
+		return condition, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses a field followed by an operator and its value
+func (p *queryParser) parseComparison() (Condition, error) {
+	field, err := p.parseField()
+	if err != nil {
+		return Condition{}, err
+	}
+
+	t := p.peek()
+	if t.kind == queryTokenOperator {
+		p.next()
+		value, err := p.parseValue()
+		if err != nil {
+			return Condition{}, err
+		}
+		switch t.text {
+		case "=", "==":
+			return Eq(field, value), nil
+		case "!=":
+			return Ne(field, value), nil
+		case ">":
+			return Gt(field, value), nil
+		case ">=":
+			return Gte(field, value), nil
+		case "<":
+			return Lt(field, value), nil
+		case "<=":
+			return Lte(field, value), nil
+		}
+		return Condition{}, p.errorAt(t.pos, fmt.Sprintf("unknown operator %s", t))
+	}
+
+	negated := false
+	if p.peekKeyword("not") {
+		p.next()
+		negated = true
+	}
+	t = p.peek()
+	switch {
+	case t.isKeyword("in"):
+		p.next()
+		values, err := p.parseList()
+		if err != nil {
+			return Condition{}, err
+		}
+		if negated {
+			return Nin(field, values...), nil
+		}
+		return In(field, values...), nil
+	case t.isKeyword("like"):
+		p.next()
+		if p.peek().kind != queryTokenString {
+			return Condition{}, p.errorExpected("string")
+		}
+		value, _ := p.literal(p.next())
+		if negated {
+			return Not(Like(field, value.(string))), nil
+		}
+		return Like(field, value.(string)), nil
+	case t.isKeyword("exists"):
+		p.next()
+		return Exists(field, !negated), nil
+	case !negated && t.isKeyword("all"):
+		p.next()
+		values, err := p.parseList()
+		if err != nil {
+			return Condition{}, err
+		}
+		return All(field, values...), nil
+	case !negated && t.isKeyword("size"):
+		p.next()
+		size := p.peek()
+		n, err := strconv.Atoi(size.text)
+		if size.kind != queryTokenNumber || err != nil || n < 0 {
+			return Condition{}, p.errorExpected("size")
+		}
+		p.next()
+		return Size(field, n), nil
+	}
+	if negated {
+		return Condition{}, p.errorExpected(`"in", "like" or "exists"`)
+	}
+	return Condition{}, p.errorExpected("operator")
+}
+
+// parseField parses a field name. Keywords are fields too, since fields are
+// only parsed where no keyword is expected.
+func (p *queryParser) parseField() (string, error) {
+	t := p.peek()
+	if t.kind != queryTokenIdent && t.kind != queryTokenField {
+		return "", p.errorExpected("field")
+	}
+	p.next()
+	return t.text, nil
+}
+
+// parseValue parses a literal value or a list of them
+func (p *queryParser) parseValue() (interface{}, error) {
+	if p.peek().is(queryTokenPunct, "[") {
+		return p.parseList()
+	}
+	value, ok := p.literal(p.peek())
+	if !ok {
+		return nil, p.errorExpected("value")
+	}
+	p.next()
+	return value, nil
+}
+
+// parseList parses a list of values between brackets
+func (p *queryParser) parseList() ([]interface{}, error) {
+	if err := p.expectPunct("["); err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	if p.peek().is(queryTokenPunct, "]") {
+		p.next()
+		return values, nil
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if p.peek().is(queryTokenPunct, "]") {
+			p.next()
+			return values, nil
+		}
+		if err := p.expectPunct(","); err != nil {
+			return nil, p.errorExpected(`"," or "]"`)
+		}
+	}
+}
+
+// literal returns the value of a string, number, boolean or null token
+func (p *queryParser) literal(t queryToken) (interface{}, bool) {
+	switch {
+	case t.kind == queryTokenString:
+		var value string
+		json.Unmarshal([]byte(t.text), &value)
+		return value, true
+	case t.kind == queryTokenNumber:
+		return json.Number(t.text), true
+	case t.isKeyword("true"):
+		return true, true
+	case t.isKeyword("false"):
+		return false, true
+	case t.isKeyword("null"):
+		return nil, true
+	}
+	return nil, false
+}
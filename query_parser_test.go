@@ -0,0 +1,121 @@
+package corbel
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQueryParseConditions(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`status = "active"`, `[{"$eq":{"status":"active"}}]`},
+		{`status == "active" and age >= 18 and tags in ["a","b"]`, `[{"$eq":{"status":"active"}},{"$gte":{"age":18}},{"$in":{"tags":["a","b"]}}]`},
+		{`price > 1.5e2 AND price < -3 and price <= 0.5 and name != null`, `[{"$gt":{"price":1.5e2}},{"$lt":{"price":-3}},{"$lte":{"price":0.5}},{"$ne":{"name":null}}]`},
+		{`a = 1 or b = true and c = false`, `[{"$or":[[{"$eq":{"a":1}}],[{"$eq":{"b":true}},{"$eq":{"c":false}}]]}]`},
+		{`(a = 1 or b = 2) and c = 3`, `[{"$or":[[{"$eq":{"a":1}}],[{"$eq":{"b":2}}]]},{"$eq":{"c":3}}]`},
+		{`tags not in [] and tags all ["x"] and tags size 2`, `[{"$nin":{"tags":[]}},{"$all":{"tags":["x"]}},{"$size":{"tags":2}}]`},
+		{`name like "te\"st" and name not like "x"`, `[{"$like":{"name":"te\"st"}},{"$not":{"$like":{"name":"x"}}}]`},
+		{`owner exists and not (deleted exists) and shared not exists`, `[{"$exists":{"owner":true}},{"$not":{"$exists":{"deleted":true}}},{"$exists":{"shared":false}}]`},
+		{`meta.created_at >= "2016-01-01" and test:field = "ñ"`, `[{"$gte":{"meta.created_at":"2016-01-01"}},{"$eq":{"test:field":"ñ"}}]`},
+		{`size > 3 and order = 1 and in in [1] and desc exists and and = true and not = 2`, `[{"$gt":{"size":3}},{"$eq":{"order":1}},{"$in":{"in":[1]}},{"$exists":{"desc":true}},{"$eq":{"and":true}},{"$eq":{"not":2}}]`},
+		{"`first name` = \"Ann\" and `not` exists and `a``b` size 1", `[{"$eq":{"first name":"Ann"}},{"$exists":{"not":true}},{"$size":{"a` + "`" + `b":1}}]`},
+	}
+
+	for _, test := range tests {
+		conditions, err := ParseConditions(test.query)
+		if err != nil {
+			t.Errorf("ParseConditions(%q) returned error: %v", test.query, err)
+			continue
+		}
+		if got, want := conditions.String(), test.want; got != want {
+			t.Errorf("Error in ParseConditions(%q). Got: %v, Want: %v", test.query, got, want)
+		}
+	}
+}
+
+func TestQueryParseConditionsErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{``, `Search: Query syntax error at column 1: expected field, got end of query`},
+		{`status`, `Search: Query syntax error at column 7: expected operator, got end of query`},
+		{`status = `, `Search: Query syntax error at column 10: expected value, got end of query`},
+		{`status = "active" and`, `Search: Query syntax error at column 22: expected field, got end of query`},
+		{`status = "active`, `Search: Query syntax error at column 10: unterminated string`},
+		{`age >= 1.2.3`, `Search: Query syntax error at column 8: invalid number "1.2.3"`},
+		{`age ! 3`, `Search: Query syntax error at column 5: unexpected "!", expected "!="`},
+		{`ñame # 3`, `Search: Query syntax error at column 6: unexpected character '#'`},
+		{`tags in ["a" "b"]`, `Search: Query syntax error at column 14: expected "," or "]", got "\"b\""`},
+		{`(a = 1 or b = 2`, `Search: Query syntax error at column 16: expected ")", got end of query`},
+		{`a = 1 b = 2`, `Search: Query syntax error at column 7: expected end of query, got "b"`},
+		{`tags size -1`, `Search: Query syntax error at column 11: expected size, got "-1"`},
+		{`a not = 1`, `Search: Query syntax error at column 7: expected "in", "like" or "exists", got "="`},
+		{`= 1`, `Search: Query syntax error at column 1: expected field, got "="`},
+		{"`name = 1", `Search: Query syntax error at column 1: unterminated field`},
+		{"`` = 1", `Search: Query syntax error at column 1: empty field`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseConditions(test.query)
+		var syntaxErr *QuerySyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("ParseConditions(%q) must return a syntax error. Got: %v", test.query, err)
+			continue
+		}
+		if got, want := err.Error(), test.want; got != want {
+			t.Errorf("Error in ParseConditions(%q) error. Got: %v, Want: %v", test.query, got, want)
+		}
+	}
+}
+
+func TestQuerySearchParse(t *testing.T) {
+	search := NewSearch(nil, "resources", "/v1.0/resource/test:Collection")
+	if err := search.Parse(`status = "active" order by name, age desc, id asc`); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got, want := search.apiQuery(), `[{"$eq":{"status":"active"}}]`; got != want {
+		t.Errorf("Error in parsed api:query. Got: %v, Want: %v", got, want)
+	}
+	if got, want := search.Sort.string(), `{"name":"asc","age":"desc","id":"asc"}`; got != want {
+		t.Errorf("Error in parsed api:sort. Got: %v, Want: %v", got, want)
+	}
+
+	search = NewSearch(nil, "resources", "/v1.0/resource/test:Collection")
+	if err := search.Parse(`order by name desc`); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got, want := search.apiQuery(), ``; got != want {
+		t.Errorf("Error in parsed api:query. Got: %v, Want: %v", got, want)
+	}
+	if got, want := search.Sort.string(), `{"name":"desc"}`; got != want {
+		t.Errorf("Error in parsed api:sort. Got: %v, Want: %v", got, want)
+	}
+
+	err := search.Parse(`order by age, name asc`)
+	if got, want := err.Error(), `Search: Query syntax error at column 15: field "name" is sorted more than once`; got != want {
+		t.Errorf("Error in Parse error. Got: %v, Want: %v", got, want)
+	}
+
+	search = NewSearch(nil, "resources", "/v1.0/resource/test:Collection")
+	if err := search.Parse(`order = 1 order by asc desc, ` + "`order`"); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got, want := search.apiQuery(), `[{"$eq":{"order":1}}]`; got != want {
+		t.Errorf("Error in parsed api:query. Got: %v, Want: %v", got, want)
+	}
+	if got, want := search.Sort.string(), `{"asc":"desc","order":"asc"}`; got != want {
+		t.Errorf("Error in parsed api:sort. Got: %v, Want: %v", got, want)
+	}
+
+	search = NewSearch(nil, "resources", "/v1.0/resource/test:Collection")
+	err = search.Parse(`status = "active" order name`)
+	if got, want := err.Error(), `Search: Query syntax error at column 25: expected "by", got "name"`; got != want {
+		t.Errorf("Error in Parse error. Got: %v, Want: %v", got, want)
+	}
+	if got, want := search.apiQuery(), ``; got != want {
+		t.Errorf("Parse must not modify the search on error. Got: %v, Want: %v", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+package corbel
+
+import "encoding/json"
+
+// QuerySpec is a search specification that is not bound to any client or
+// collection. It's immutable, every method returns a modified copy, so it can
+// be defined once and shared between goroutines.
+//
+//	active := NewQuerySpec().Where(Eq("status", "active")).OrderBy(SortKey{"name", SortAsc})
+//	err := active.Bind(client.Resources.SearchCollection("test:User")).All(&users)
+//	err = active.Bind(otherClient.Resources.SearchCollection("test:User")).All(&otherUsers)
+type QuerySpec struct {
+	conditions Conditions
+	sort       []SortKey
+	text       *textSearch
+	fields     []string
+	distinct   []string
+	pageSize   int
+}
+
+// querySpecJSON is the JSON representation of a QuerySpec
+type querySpecJSON struct {
+	Query    Conditions  `json:"query,omitempty"`
+	Sort     []SortKey   `json:"sort,omitempty"`
+	Search   *textSearch `json:"search,omitempty"`
+	Select   []string    `json:"select,omitempty"`
+	Distinct []string    `json:"distinct,omitempty"`
+	PageSize int         `json:"pageSize,omitempty"`
+}
+
+// NewQuerySpec returns an empty QuerySpec
+func NewQuerySpec() QuerySpec {
+	return QuerySpec{}
+}
+
+// Where returns a copy of the spec with conditions added
+func (q QuerySpec) Where(conditions ...Condition) QuerySpec {
+	q.conditions = append(q.conditions[:len(q.conditions):len(q.conditions)], conditions...)
+	return q
+}
+
+// OrderBy returns a copy of the spec with keys added after the existing ones
+func (q QuerySpec) OrderBy(keys ...SortKey) QuerySpec {
+	q.sort = append(q.sort[:len(q.sort):len(q.sort)], keys...)
+	return q
+}
+
+// TextSearch returns a copy of the spec with the full text search replaced.
+// See Search.TextSearch.
+func (q QuerySpec) TextSearch(text string, fields ...string) QuerySpec {
+	q.text = &textSearch{Text: text, Fields: append([]string(nil), fields...)}
+	return q
+}
+
+// Select returns a copy of the spec with the fields to return replaced.
+// See Search.Select.
+func (q QuerySpec) Select(fields ...string) QuerySpec {
+	q.fields = append([]string(nil), fields...)
+	return q
+}
+
+// Distinct returns a copy of the spec with the distinct fields replaced.
+// See Search.Distinct.
+func (q QuerySpec) Distinct(fields ...string) QuerySpec {
+	q.distinct = append([]string(nil), fields...)
+	return q
+}
+
+// WithPageSize returns a copy of the spec with the page size replaced
+func (q QuerySpec) WithPageSize(pageSize int) QuerySpec {
+	q.pageSize = pageSize
+	return q
+}
+
+// Clone returns a copy of the spec that doesn't share memory with it
+func (q QuerySpec) Clone() QuerySpec {
+	q.conditions = append(Conditions(nil), q.conditions...)
+	q.sort = append([]SortKey(nil), q.sort...)
+	if q.text != nil {
+		text := *q.text
+		text.Fields = append([]string(nil), text.Fields...)
+		q.text = &text
+	}
+	q.fields = append([]string(nil), q.fields...)
+	q.distinct = append([]string(nil), q.distinct...)
+	return q
+}
+
+// Bind returns a new Search over the same client and collection as target
+// with the spec applied. target is not modified.
+func (q QuerySpec) Bind(target *Search) *Search {
+	s := NewSearch(target.client, target.endpoint, target.baseURL)
+	s.Where(q.conditions...)
+	s.OrderBy(q.sort...)
+	s.text = q.text
+	s.fields = q.fields
+	s.distinct = q.distinct
+	if q.pageSize > 0 {
+		s.PageSize = q.pageSize
+	}
+	return s
+}
+
+// MarshalJSON returns the spec as JSON
+func (q QuerySpec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(querySpecJSON{
+		Query:    q.conditions,
+		Sort:     q.sort,
+		Search:   q.text,
+		Select:   q.fields,
+		Distinct: q.distinct,
+		PageSize: q.pageSize,
+	})
+}
+
+// UnmarshalJSON reads a spec from JSON as returned by MarshalJSON
+func (q *QuerySpec) UnmarshalJSON(data []byte) error {
+	var spec querySpecJSON
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	*q = QuerySpec{
+		conditions: spec.Query,
+		sort:       spec.Sort,
+		text:       spec.Search,
+		fields:     spec.Select,
+		distinct:   spec.Distinct,
+		pageSize:   spec.PageSize,
+	}
+	return nil
+}
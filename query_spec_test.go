@@ -0,0 +1,134 @@
+package corbel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestQuerySpecImmutable(t *testing.T) {
+	base := NewQuerySpec().Where(Eq("status", "active")).OrderBy(SortKey{"name", SortAsc})
+
+	adults := base.Where(Gte("age", 18))
+	children := base.Where(Lt("age", 18))
+	byAge := base.OrderBy(SortKey{"age", SortDesc})
+
+	target := NewSearch(nil, "resources", "/v1.0/resource/test:User")
+	tests := []struct {
+		spec  QuerySpec
+		query string
+		sort  string
+	}{
+		{base, `[{"$eq":{"status":"active"}}]`, `{"name":"asc"}`},
+		{adults, `[{"$eq":{"status":"active"}},{"$gte":{"age":18}}]`, `{"name":"asc"}`},
+		{children, `[{"$eq":{"status":"active"}},{"$lt":{"age":18}}]`, `{"name":"asc"}`},
+		{byAge, `[{"$eq":{"status":"active"}}]`, `{"name":"asc","age":"desc"}`},
+	}
+	for _, test := range tests {
+		search := test.spec.Bind(target)
+		if got, want := search.apiQuery(), test.query; got != want {
+			t.Errorf("Error in bound api:query. Got: %v, Want: %v", got, want)
+		}
+		if got, want := search.Sort.string(), test.sort; got != want {
+			t.Errorf("Error in bound api:sort. Got: %v, Want: %v", got, want)
+		}
+	}
+
+	search := base.Bind(target)
+	search.Where(Eq("other", 1))
+	search.OrderBy(SortKey{"other", SortAsc})
+	if got, want := base.Bind(target).apiQuery(), `[{"$eq":{"status":"active"}}]`; got != want {
+		t.Errorf("Modifying a bound search must not modify the spec. Got: %v, Want: %v", got, want)
+	}
+	if got, want := target.apiQuery(), ``; got != want {
+		t.Errorf("Bind must not modify the target. Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestQuerySpecJSON(t *testing.T) {
+	spec := NewQuerySpec().
+		Where(
+			Eq("status", "active"),
+			Gt("price", 10.5),
+			Nin("tags", "a", 1),
+			Or(Eq("a", true), And(Ne("b", nil), Size("c", 2))),
+			Not(Like("name", "^test")),
+			ElemMatch("items", Eq("id", "x"), Lte("qty", 3)),
+		).
+		OrderBy(SortKey{"name", SortAsc}, SortKey{"age", SortDesc}).
+		TextSearch("blue", "description").
+		Select("name", "price").
+		Distinct("status").
+		WithPageSize(50)
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	want := `{"query":[{"$eq":{"status":"active"}},{"$gt":{"price":10.5}},{"$nin":{"tags":["a",1]}},` +
+		`{"$or":[[{"$eq":{"a":true}}],[{"$ne":{"b":null}},{"$size":{"c":2}}]]},{"$not":{"$like":{"name":"^test"}}},` +
+		`{"$elemMatch":{"items":[{"$eq":{"id":"x"}},{"$lte":{"qty":3}}]}}],` +
+		`"sort":[{"field":"name","direction":"asc"},{"field":"age","direction":"desc"}],` +
+		`"search":{"text":"blue","fields":["description"]},"select":["name","price"],"distinct":["status"],"pageSize":50}`
+	if got := string(data); got != want {
+		t.Errorf("Error in spec JSON. Got: %v, Want: %v", got, want)
+	}
+
+	var decoded QuerySpec
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	data, err = json.Marshal(decoded.Clone())
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if got := string(data); got != want {
+		t.Errorf("Error in decoded spec JSON. Got: %v, Want: %v", got, want)
+	}
+
+	invalid := []string{
+		`{"query":[{"$eq":{"a":1,"b":2}}]}`,
+		`{"query":[{"$eq":{"a":1},"$gt":{"b":2}}]}`,
+		`{"query":[{"eq":{"a":1}}]}`,
+	}
+	for _, data := range invalid {
+		if err := json.Unmarshal([]byte(data), &decoded); err != errInvalidCondition {
+			t.Errorf("Unmarshal(%s) must fail. Got: %v", data, err)
+		}
+	}
+}
+
+func TestQuerySpecBindOffline(t *testing.T) {
+	var requests []url.Values
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query())
+		w.Write([]byte(`[]`))
+	}))
+
+	spec := NewQuerySpec().Where(Eq("status", "active")).Select("name").WithPageSize(5)
+	var result []map[string]interface{}
+	for _, target := range []*Search{
+		client.Resources.SearchCollection("test:User"),
+		client.Resources.SearchRelation("test:Group", "1", "test:Users"),
+	} {
+		if err := spec.Bind(target).Page(0, &result); err != nil {
+			t.Fatalf("Page returned error: %v", err)
+		}
+	}
+
+	if got, want := len(requests), 2; got != want {
+		t.Fatalf("Error in requests sent. Got: %v, Want: %v", got, want)
+	}
+	for _, request := range requests {
+		if got, want := request.Get("api:query"), `[{"$eq":{"status":"active"}}]`; got != want {
+			t.Errorf("Error in api:query. Got: %v, Want: %v", got, want)
+		}
+		if got, want := request.Get("api:select"), "name"; got != want {
+			t.Errorf("Error in api:select. Got: %v, Want: %v", got, want)
+		}
+		if got, want := request.Get("api:pageSize"), "5"; got != want {
+			t.Errorf("Error in api:pageSize. Got: %v, Want: %v", got, want)
+		}
+	}
+}
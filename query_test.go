@@ -0,0 +1,78 @@
+package corbel
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestQueryConditions(t *testing.T) {
+	tests := []struct {
+		conditions Conditions
+		want       string
+	}{
+		{Conditions{}, ``},
+		{Conditions{Eq("name", "test")}, `[{"$eq":{"name":"test"}}]`},
+		{Conditions{Ne("age", 3)}, `[{"$ne":{"age":3}}]`},
+		{Conditions{Gt("price", 1.5), Lte("price", 10)}, `[{"$gt":{"price":1.5}},{"$lte":{"price":10}}]`},
+		{Conditions{Gte("name", "b"), Lt("active", true)}, `[{"$gte":{"name":"b"}},{"$lt":{"active":true}}]`},
+		{Conditions{Gte("date", time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC))}, `[{"$gte":{"date":"2016-01-02T03:04:05Z"}}]`},
+		{Conditions{In("tag", "a", 1)}, `[{"$in":{"tag":["a",1]}}]`},
+		{Conditions{Nin("tag", "a", "b")}, `[{"$nin":{"tag":["a","b"]}}]`},
+		{Conditions{All("tag", "a")}, `[{"$all":{"tag":["a"]}}]`},
+		{Conditions{Like("name", "te.*")}, `[{"$like":{"name":"te.*"}}]`},
+		{Conditions{Exists("name", false)}, `[{"$exists":{"name":false}}]`},
+		{Conditions{Size("tags", 2)}, `[{"$size":{"tags":2}}]`},
+		{Conditions{Not(Eq("name", "test"))}, `[{"$not":{"$eq":{"name":"test"}}}]`},
+		{Conditions{ElemMatch("items", Eq("id", 1), Gt("qty", 2))}, `[{"$elemMatch":{"items":[{"$eq":{"id":1}},{"$gt":{"qty":2}}]}}]`},
+		{Conditions{And(Eq("a", 1), Eq("b", 2)), Eq("c", 3)}, `[{"$eq":{"a":1}},{"$eq":{"b":2}},{"$eq":{"c":3}}]`},
+		{Conditions{Or(Eq("a", 1), And(Eq("b", 2), Nin("c", 3)))}, `[{"$or":[[{"$eq":{"a":1}}],[{"$eq":{"b":2}},{"$nin":{"c":[3]}}]]}]`},
+	}
+
+	for _, test := range tests {
+		if got, want := test.conditions.String(), test.want; got != want {
+			t.Errorf("Error in conditions String. Got: %v, Want: %v", got, want)
+		}
+	}
+}
+
+func TestSearchWhere(t *testing.T) {
+	search := NewSearch(nil, "resources", "/v1.0/resource/test:Collection")
+
+	if got, want := search.apiQuery(), ``; got != want {
+		t.Errorf("Error in empty search api:query. Got: %v, Want: %v", got, want)
+	}
+
+	search.Query.Eq["name"] = "test"
+	if got, want := search.apiQuery(), `[{"$eq":{"name":"test"}}]`; got != want {
+		t.Errorf("Error in search api:query without conditions. Got: %v, Want: %v", got, want)
+	}
+
+	search.Where(Gt("price", 2.5)).Where(Or(Eq("a", 1), Eq("b", 2)))
+	if got, want := search.apiQuery(), `[{"$eq":{"name":"test"}},{"$gt":{"price":2.5}},{"$or":[[{"$eq":{"a":1}}],[{"$eq":{"b":2}}]]}]`; got != want {
+		t.Errorf("Error in search api:query with conditions. Got: %v, Want: %v", got, want)
+	}
+
+	search = NewSearch(nil, "resources", "/v1.0/resource/test:Collection")
+	search.Where(Ne("name", nil))
+	if got, want := search.apiQuery(), `[{"$ne":{"name":null}}]`; got != want {
+		t.Errorf("Error in search api:query with only conditions. Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestSearchWherePageContext(t *testing.T) {
+	var apiQuery string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiQuery = r.URL.Query().Get("api:query")
+		w.Write([]byte(`[]`))
+	}))
+
+	var result []map[string]interface{}
+	search := client.Resources.SearchCollection("test:Collection").Where(In("tag", "a", "b"))
+	if err := search.Page(0, &result); err != nil {
+		t.Fatalf("Page returned error: %v", err)
+	}
+	if got, want := apiQuery, `[{"$in":{"tag":["a","b"]}}]`; got != want {
+		t.Errorf("Error in api:query sent. Got: %v, Want: %v", got, want)
+	}
+}
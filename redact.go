@@ -0,0 +1,64 @@
+package corbel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces the sensitive values in the logs
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are the parts of a field or JSON key name whose values are
+// always redacted
+var sensitiveKeys = []string{"password", "secret", "token", "assertion", "authorization", "basic_auth"}
+
+var (
+	redactBearer = regexp.MustCompile(`(?i)(bearer\s+)[^\s"\]]+`)
+	redactJWT    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	redactJSON   = regexp.MustCompile(`(?i)("[\w.]*(?:password|secret|token|assertion|basic_auth)[\w.]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	redactForm   = regexp.MustCompile(`(?i)((?:assertion|[\w.]*password|[\w.]*token)=)[^&\s]+`)
+)
+
+// redact masks tokens, secrets and passwords found in s
+func redact(s string) string {
+	s = redactBearer.ReplaceAllString(s, "${1}"+redacted)
+	s = redactJWT.ReplaceAllString(s, redacted)
+	s = redactJSON.ReplaceAllString(s, `${1}"`+redacted+`"`)
+	s = redactForm.ReplaceAllString(s, "${1}"+redacted)
+	return s
+}
+
+// redactFields returns a copy of fields with the sensitive values masked
+func redactFields(fields Fields) Fields {
+	if fields == nil {
+		return nil
+	}
+	clean := make(Fields, len(fields))
+	for key, value := range fields {
+		if isSensitiveKey(key) {
+			clean[key] = redacted
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			clean[key] = redact(v)
+		case int, int64, uint64, float64, bool, nil:
+			clean[key] = v
+		default:
+			clean[key] = redact(fmt.Sprintf("%v", v))
+		}
+	}
+	return clean
+}
+
+// isSensitiveKey looks if the values of key must be redacted
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package corbel
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`map[Authorization:[Bearer abc.def-123]]`, `map[Authorization:[Bearer [REDACTED]]]`},
+		{`{"username":"user","password":"p4ss\"word"}`, `{"username":"user","password":"[REDACTED]"}`},
+		{`{"accessToken":"abc","refreshToken":"def","expiresAt":1}`, `{"accessToken":"[REDACTED]","refreshToken":"[REDACTED]","expiresAt":1}`},
+		{`{"basic_auth.password":"secret"}`, `{"basic_auth.password":"[REDACTED]"}`},
+		{`grant_type=jwt&assertion=abc.def.ghi`, `grant_type=jwt&assertion=[REDACTED]`},
+		{`token eyJhbGciOiJIUzI1NiJ9.eyJpc3MiOiJpZCJ9.sig`, `token [REDACTED]`},
+		{`nothing to hide`, `nothing to hide`},
+	}
+	for _, test := range tests {
+		if got := redact(test.in); got != test.want {
+			t.Errorf("redact(%q) is %q, but want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestRedactFields(t *testing.T) {
+	fields := redactFields(Fields{
+		"clientSecret":  "secret",
+		"Authorization": "Bearer abc",
+		"body":          `{"password":"secret"}`,
+		"code":          401,
+	})
+	want := Fields{
+		"clientSecret":  redacted,
+		"Authorization": redacted,
+		"body":          `{"password":"[REDACTED]"}`,
+		"code":          401,
+	}
+	for key, value := range want {
+		if got := fields[key]; got != value {
+			t.Errorf("redactFields[%s] is %v, but want %v", key, got, value)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package corbel
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -19,24 +20,39 @@ type UserACL struct {
 	Properties map[string]interface{} `json:"properties"`
 }
 
-func (r *ResourcesService) createRequest(method, accept, uri string, body interface{}) (*http.Request, error) {
-	return r.client.NewRequestContentType(method, "resources", uri, "application/json", accept, body)
+func (r *ResourcesService) createRequest(ctx context.Context, method, accept, uri string, body interface{}) (*http.Request, error) {
+	return r.client.NewRequestContentTypeContext(ctx, method, "resources", uri, "application/json", accept, body)
 }
 
 // CollectionRequest perform a specific collection request on resources
 func (r *ResourcesService) CollectionRequest(method, accept, collectionName string, send interface{}) (*http.Request, error) {
+	return r.CollectionRequestContext(context.Background(), method, accept, collectionName, send)
+}
+
+// CollectionRequestContext is like CollectionRequest but binds ctx to the request.
+func (r *ResourcesService) CollectionRequestContext(ctx context.Context, method, accept, collectionName string, send interface{}) (*http.Request, error) {
 	uri := fmt.Sprintf("/v1.0/resource/%s", collectionName)
-	return r.createRequest(method, accept, uri, send)
+	return r.createRequest(ctx, method, accept, uri, send)
 }
 
 // ResourceRequest perform a specific resource request on resources
 func (r *ResourcesService) ResourceRequest(method, accept, collectionName, id string, send interface{}) (*http.Request, error) {
+	return r.ResourceRequestContext(context.Background(), method, accept, collectionName, id, send)
+}
+
+// ResourceRequestContext is like ResourceRequest but binds ctx to the request.
+func (r *ResourcesService) ResourceRequestContext(ctx context.Context, method, accept, collectionName, id string, send interface{}) (*http.Request, error) {
 	uri := fmt.Sprintf("/v1.0/resource/%s/%s", collectionName, id)
-	return r.createRequest(method, accept, uri, send)
+	return r.createRequest(ctx, method, accept, uri, send)
 }
 
 // RelationRequest perform a specific relation request on resources
 func (r *ResourcesService) RelationRequest(method, accept, collectionName, resourceID, relationName, relatedCollectionName, relatedID string, send interface{}) (*http.Request, error) {
+	return r.RelationRequestContext(context.Background(), method, accept, collectionName, resourceID, relationName, relatedCollectionName, relatedID, send)
+}
+
+// RelationRequestContext is like RelationRequest but binds ctx to the request.
+func (r *ResourcesService) RelationRequestContext(ctx context.Context, method, accept, collectionName, resourceID, relationName, relatedCollectionName, relatedID string, send interface{}) (*http.Request, error) {
 	uri := fmt.Sprintf("/v1.0/resource/%s/%s/%s", collectionName, resourceID, relationName)
 	if relatedCollectionName != "" || relatedID != "" {
 		uri = fmt.Sprintf("%s;r=%s", uri, relatedCollectionName)
@@ -44,5 +60,5 @@ func (r *ResourcesService) RelationRequest(method, accept, collectionName, resou
 	if relatedID != "" {
 		uri = fmt.Sprintf("%s/%s", uri, relatedID)
 	}
-	return r.createRequest(method, accept, uri, send)
+	return r.createRequest(ctx, method, accept, uri, send)
 }
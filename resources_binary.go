@@ -0,0 +1,91 @@
+package corbel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// UploadToCollection stores body as the representation of the resource with
+// the media type contentType, like image/png or application/pdf. body is
+// streamed, so it's not read in memory, and if it's an io.ReadCloser it's
+// closed once sent.
+func (r *ResourcesService) UploadToCollection(collectionName, id, contentType string, body io.Reader) error {
+	return r.UploadToCollectionContext(context.Background(), collectionName, id, contentType, body)
+}
+
+// UploadToCollectionContext is like UploadToCollection but uses ctx for the request.
+func (r *ResourcesService) UploadToCollectionContext(ctx context.Context, collectionName, id, contentType string, body io.Reader) error {
+	uri := fmt.Sprintf("/v1.0/resource/%s/%s", collectionName, id)
+	req, err := r.client.NewRequestContentTypeContext(ctx, "PUT", "resources", uri, contentType, "application/json", body)
+	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
+	return err
+}
+
+// DownloadFromCollection returns the representation of the resource with the
+// media type accept. The content is streamed from the platform while it's
+// read, and it must be closed once done.
+func (r *ResourcesService) DownloadFromCollection(collectionName, id, accept string) (io.ReadCloser, error) {
+	return r.DownloadFromCollectionContext(context.Background(), collectionName, id, accept)
+}
+
+// DownloadFromCollectionContext is like DownloadFromCollection but uses ctx for the request.
+func (r *ResourcesService) DownloadFromCollectionContext(ctx context.Context, collectionName, id, accept string) (io.ReadCloser, error) {
+	return r.download(ctx, collectionName, id, accept, "")
+}
+
+// DownloadRangeFromCollection is like DownloadFromCollection but only returns
+// length bytes of the content starting at offset. If length is 0 or less it
+// returns the content from offset until its end.
+func (r *ResourcesService) DownloadRangeFromCollection(collectionName, id, accept string, offset, length int64) (io.ReadCloser, error) {
+	return r.DownloadRangeFromCollectionContext(context.Background(), collectionName, id, accept, offset, length)
+}
+
+// DownloadRangeFromCollectionContext is like DownloadRangeFromCollection but uses ctx for the request.
+func (r *ResourcesService) DownloadRangeFromCollectionContext(ctx context.Context, collectionName, id, accept string, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 {
+		return nil, errInvalidRange
+	}
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		byteRange = fmt.Sprintf("%s%d", byteRange, offset+length-1)
+	}
+	return r.download(ctx, collectionName, id, accept, byteRange)
+}
+
+// download requests the representation of the resource with the media type
+// accept, or only the part in byteRange if not empty, and returns its body
+// without reading it.
+func (r *ResourcesService) download(ctx context.Context, collectionName, id, accept, byteRange string) (io.ReadCloser, error) {
+	uri := fmt.Sprintf("/v1.0/resource/%s/%s", collectionName, id)
+	req, err := r.client.NewRequestContentTypeContext(ctx, "GET", "resources", uri, "application/json", accept, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(withStreaming(req.Context()))
+
+	desiredStatusCode := http.StatusOK
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+		desiredStatusCode = http.StatusPartialContent
+	}
+
+	res, err := r.client.do(req)
+	if err != nil {
+		r.client.logger.Debugf("failed to make request: %v", err)
+		return nil, err
+	}
+	if res.StatusCode == desiredStatusCode {
+		return res.Body, nil
+	}
+
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if byteRange != "" && res.StatusCode == http.StatusOK {
+		return nil, errRangeNotSupported
+	}
+	_, err = returnErrorByHTTPStatusCode(res, body, desiredStatusCode)
+	return nil, err
+}
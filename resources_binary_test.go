@@ -0,0 +1,133 @@
+package corbel
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// onlyReader hides every method of the reader but Read, so the request body
+// can't be rewound or buffered
+type onlyReader struct {
+	io.Reader
+}
+
+func newBinaryTestClient(t *testing.T, content string, requests *int) *Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if r.URL.Path != "/v1.0/resource/test:Images/1" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+			return
+		}
+		if r.Method != "GET" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+			return
+		}
+		if got, want := r.Header.Get("Accept"), "image/png"; got != want {
+			t.Errorf("Error in Accept header. Got: %v, Want: %v", got, want)
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	}))
+}
+
+func TestResourcesBinaryUploadOffline(t *testing.T) {
+	content := strings.Repeat("0123456789", 1000)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/v1.0/resource/test:Images/1" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+			return
+		}
+		if got, want := r.Header.Get("Content-Type"), "image/png"; got != want {
+			t.Errorf("Error in Content-Type header. Got: %v, Want: %v", got, want)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if got, want := string(body), content; got != want {
+			t.Errorf("Error in uploaded body. Got %d bytes, Want %d bytes", len(got), len(want))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	err := client.Resources.UploadToCollection("test:Images", "1", "image/png", onlyReader{strings.NewReader(content)})
+	if err != nil {
+		t.Errorf("UploadToCollection returned error: %v", err)
+	}
+}
+
+func TestResourcesBinaryDownloadOffline(t *testing.T) {
+	content, requests := "0123456789", 0
+	client := newBinaryTestClient(t, content, &requests)
+	client.Cache = NewLRUCache(DefaultCacheSize)
+
+	for i := 0; i < 2; i++ {
+		body, err := client.Resources.DownloadFromCollection("test:Images", "1", "image/png")
+		if err != nil {
+			t.Fatalf("DownloadFromCollection returned error: %v", err)
+		}
+		got, _ := io.ReadAll(body)
+		body.Close()
+		if want := content; string(got) != want {
+			t.Errorf("Error in DownloadFromCollection. Got: %s, Want: %s", got, want)
+		}
+	}
+	if got, want := requests, 2; got != want {
+		t.Errorf("Error in DownloadFromCollection, downloads must not be cached. Got: %v requests, Want: %v", got, want)
+	}
+}
+
+func TestResourcesBinaryDownloadRangeOffline(t *testing.T) {
+	content, requests := "0123456789", 0
+	client := newBinaryTestClient(t, content, &requests)
+
+	for _, test := range []struct {
+		offset, length int64
+		want           string
+	}{
+		{2, 3, "234"},
+		{7, 0, "789"},
+		{0, 1, "0"},
+	} {
+		body, err := client.Resources.DownloadRangeFromCollection("test:Images", "1", "image/png", test.offset, test.length)
+		if err != nil {
+			t.Errorf("DownloadRangeFromCollection(%d, %d) returned error: %v", test.offset, test.length, err)
+			continue
+		}
+		got, _ := io.ReadAll(body)
+		body.Close()
+		if string(got) != test.want {
+			t.Errorf("Error in DownloadRangeFromCollection(%d, %d). Got: %s, Want: %s", test.offset, test.length, got, test.want)
+		}
+	}
+
+	if _, err := client.Resources.DownloadRangeFromCollection("test:Images", "1", "image/png", -1, 2); err != errInvalidRange {
+		t.Errorf("Error in DownloadRangeFromCollection with negative offset. Got: %v, Want: %v", err, errInvalidRange)
+	}
+
+	_, err := client.Resources.DownloadRangeFromCollection("test:Images", "1", "image/png", 20, 2)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("Error in DownloadRangeFromCollection out of range. Got: %v, Want: APIError 416", err)
+	}
+}
+
+func TestResourcesBinaryDownloadErrorsOffline(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			w.Write([]byte("0123456789"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	if _, err := client.Resources.DownloadFromCollection("test:Images", "1", "image/png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Error in DownloadFromCollection of missing resource. Got: %v, Want: %v", err, ErrNotFound)
+	}
+	if _, err := client.Resources.DownloadRangeFromCollection("test:Images", "1", "image/png", 2, 3); err != errRangeNotSupported {
+		t.Errorf("Error in DownloadRangeFromCollection ignoring the range. Got: %v, Want: %v", err, errRangeNotSupported)
+	}
+}
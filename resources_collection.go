@@ -1,18 +1,51 @@
 package corbel
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // AddToCollection add the required struct formated as json to the desired collection
 // resource must have exported variables and optionally its representation as JSON.
 func (r *ResourcesService) AddToCollection(collectionName string, resource interface{}) (string, error) {
-	req, err := r.CollectionRequest("POST", "application/json", collectionName, resource)
+	return r.AddToCollectionContext(context.Background(), collectionName, resource)
+}
+
+// AddToCollectionContext is like AddToCollection but uses ctx for the request.
+func (r *ResourcesService) AddToCollectionContext(ctx context.Context, collectionName string, resource interface{}) (string, error) {
+	req, err := r.CollectionRequestContext(ctx, "POST", "application/json", collectionName, resource)
 	return returnErrorHTTPSimple(r.client, req, err, 201)
 }
 
+// AddToCollectionCreated is like AddToCollection but returns the parsed
+// location of the created resource. If fetched is not nil the created
+// resource is also got from the collection into it.
+func (r *ResourcesService) AddToCollectionCreated(collectionName string, resource, fetched interface{}) (*Created, error) {
+	return r.AddToCollectionCreatedContext(context.Background(), collectionName, resource, fetched)
+}
+
+// AddToCollectionCreatedContext is like AddToCollectionCreated but uses ctx for the requests.
+func (r *ResourcesService) AddToCollectionCreatedContext(ctx context.Context, collectionName string, resource, fetched interface{}) (*Created, error) {
+	location, err := r.AddToCollectionContext(ctx, collectionName, resource)
+	if err != nil {
+		return nil, err
+	}
+	created, err := parseCreated(location)
+	if err != nil || fetched == nil {
+		return created, err
+	}
+	return created, r.GetFromCollectionContext(ctx, collectionName, created.ID, fetched)
+}
+
 // UpdateInCollection updates the required struct formated as json to the desired collection
 // resource must have exported variables and optionally its representation as JSON.
 func (r *ResourcesService) UpdateInCollection(collectionName, id string, resource interface{}) error {
-	req, err := r.ResourceRequest("PUT", "application/json", collectionName, id, resource)
+	return r.UpdateInCollectionContext(context.Background(), collectionName, id, resource)
+}
+
+// UpdateInCollectionContext is like UpdateInCollection but uses ctx for the request.
+func (r *ResourcesService) UpdateInCollectionContext(ctx context.Context, collectionName, id string, resource interface{}) error {
+	req, err := r.ResourceRequestContext(ctx, "PUT", "application/json", collectionName, id, resource)
 	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
 	return err
 }
@@ -24,21 +57,36 @@ func (r *ResourcesService) SearchCollection(collectionName string) *Search {
 
 // GetFromCollection gets the desired object from the collection by id
 func (r *ResourcesService) GetFromCollection(collectionName, id string, resource interface{}) error {
-	req, err := r.ResourceRequest("GET", "application/json", collectionName, id, nil)
+	return r.GetFromCollectionContext(context.Background(), collectionName, id, resource)
+}
+
+// GetFromCollectionContext is like GetFromCollection but uses ctx for the request.
+func (r *ResourcesService) GetFromCollectionContext(ctx context.Context, collectionName, id string, resource interface{}) error {
+	req, err := r.ResourceRequestContext(ctx, "GET", "application/json", collectionName, id, nil)
 	_, err = returnErrorHTTPInterface(r.client, req, err, resource, 200)
 	return err
 }
 
 // GetFromRelationDefinition gets the desired object from the collection by id
 func (r *ResourcesService) GetFromRelationDefinition(id string, resource interface{}) error {
-	req, err := r.client.NewRequest("GET", "resources", fmt.Sprintf("/v1.0/resource/%s", id), nil)
+	return r.GetFromRelationDefinitionContext(context.Background(), id, resource)
+}
+
+// GetFromRelationDefinitionContext is like GetFromRelationDefinition but uses ctx for the request.
+func (r *ResourcesService) GetFromRelationDefinitionContext(ctx context.Context, id string, resource interface{}) error {
+	req, err := r.client.NewRequestContext(ctx, "GET", "resources", fmt.Sprintf("/v1.0/resource/%s", id), nil)
 	_, err = returnErrorHTTPInterface(r.client, req, err, resource, 200)
 	return err
 }
 
 // DeleteFromCollection deletes the desired resource from the platform by id
 func (r *ResourcesService) DeleteFromCollection(collectionName, id string) error {
-	req, err := r.ResourceRequest("DELETE", "application/json", collectionName, id, nil)
+	return r.DeleteFromCollectionContext(context.Background(), collectionName, id)
+}
+
+// DeleteFromCollectionContext is like DeleteFromCollection but uses ctx for the request.
+func (r *ResourcesService) DeleteFromCollectionContext(ctx context.Context, collectionName, id string) error {
+	req, err := r.ResourceRequestContext(ctx, "DELETE", "application/json", collectionName, id, nil)
 	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
 	return err
 }
@@ -46,28 +94,48 @@ func (r *ResourcesService) DeleteFromCollection(collectionName, id string) error
 // UpdateResourceACL updates the acl of the associated resource. ACL entries will be added if they were not previously
 // there or modified otherwise. Any entries previously added but not passed will be removed.
 func (r *ResourcesService) UpdateResourceACL(collectionName, id string, acl interface{}) error {
-	req, err := r.ResourceRequest("PUT", "application/corbel.acl+json", collectionName, id, acl)
+	return r.UpdateResourceACLContext(context.Background(), collectionName, id, acl)
+}
+
+// UpdateResourceACLContext is like UpdateResourceACL but uses ctx for the request.
+func (r *ResourcesService) UpdateResourceACLContext(ctx context.Context, collectionName, id string, acl interface{}) error {
+	req, err := r.ResourceRequestContext(ctx, "PUT", "application/corbel.acl+json", collectionName, id, acl)
 	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
 	return err
 }
 
 // MarkCollectionAsACL is used to set a collection as ACL managed
 func (r *ResourcesService) MarkCollectionAsACL(info interface{}) error {
-	req, err := r.CollectionRequest("POST", "application/json", "acl:Configuration", info)
+	return r.MarkCollectionAsACLContext(context.Background(), info)
+}
+
+// MarkCollectionAsACLContext is like MarkCollectionAsACL but uses ctx for the request.
+func (r *ResourcesService) MarkCollectionAsACLContext(ctx context.Context, info interface{}) error {
+	req, err := r.CollectionRequestContext(ctx, "POST", "application/json", "acl:Configuration", info)
 	_, err = returnErrorHTTPSimple(r.client, req, err, 201)
 	return err
 }
 
 // UpdateACLCollection is used to update an acl managed collection
 func (r *ResourcesService) UpdateACLCollection(id string, info interface{}) error {
-	req, err := r.ResourceRequest("PUT", "application/json", "acl:Configuration", id, info)
+	return r.UpdateACLCollectionContext(context.Background(), id, info)
+}
+
+// UpdateACLCollectionContext is like UpdateACLCollection but uses ctx for the request.
+func (r *ResourcesService) UpdateACLCollectionContext(ctx context.Context, id string, info interface{}) error {
+	req, err := r.ResourceRequestContext(ctx, "PUT", "application/json", "acl:Configuration", id, info)
 	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
 	return err
 }
 
 // DeleteACLCollection is used to delete an acl managed collection
 func (r *ResourcesService) DeleteACLCollection(id string) error {
-	req, err := r.ResourceRequest("DELETE", "application/json", "acl:Configuration", id, nil)
+	return r.DeleteACLCollectionContext(context.Background(), id)
+}
+
+// DeleteACLCollectionContext is like DeleteACLCollection but uses ctx for the request.
+func (r *ResourcesService) DeleteACLCollectionContext(ctx context.Context, id string) error {
+	req, err := r.ResourceRequestContext(ctx, "DELETE", "application/json", "acl:Configuration", id, nil)
 	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
 	return err
 }
@@ -0,0 +1,111 @@
+package corbel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// modifyMaxAttempts is the number of times ModifyInCollection reads and
+// updates the resource before giving up because of concurrent updates
+const modifyMaxAttempts = 5
+
+// GetFromCollectionETag is like GetFromCollection but also returns the ETag
+// of the resource, to be used with UpdateInCollectionIfMatch and
+// DeleteFromCollectionIfMatch.
+func (r *ResourcesService) GetFromCollectionETag(collectionName, id string, resource interface{}) (string, error) {
+	return r.GetFromCollectionETagContext(context.Background(), collectionName, id, resource)
+}
+
+// GetFromCollectionETagContext is like GetFromCollectionETag but uses ctx for the request.
+func (r *ResourcesService) GetFromCollectionETagContext(ctx context.Context, collectionName, id string, resource interface{}) (string, error) {
+	req, err := r.ResourceRequestContext(ctx, "GET", "application/json", collectionName, id, nil)
+	res, _, err := returnResponseHTTPInterface(r.client, req, err, resource, 200)
+	if err != nil {
+		return "", err
+	}
+	return res.Header.Get("ETag"), nil
+}
+
+// UpdateInCollectionIfMatch is like UpdateInCollection but only updates the
+// resource if it was not modified since etag was got. Otherwise it returns an
+// error matching ErrPreconditionFailed.
+func (r *ResourcesService) UpdateInCollectionIfMatch(collectionName, id, etag string, resource interface{}) error {
+	return r.UpdateInCollectionIfMatchContext(context.Background(), collectionName, id, etag, resource)
+}
+
+// UpdateInCollectionIfMatchContext is like UpdateInCollectionIfMatch but uses ctx for the request.
+func (r *ResourcesService) UpdateInCollectionIfMatchContext(ctx context.Context, collectionName, id, etag string, resource interface{}) error {
+	if etag == "" {
+		return errMissingETag
+	}
+	req, err := r.ResourceRequestContext(ctx, "PUT", "application/json", collectionName, id, resource)
+	if err == nil {
+		req.Header.Set("If-Match", etag)
+	}
+	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
+	return err
+}
+
+// DeleteFromCollectionIfMatch is like DeleteFromCollection but only deletes
+// the resource if it was not modified since etag was got. Otherwise it
+// returns an error matching ErrPreconditionFailed.
+func (r *ResourcesService) DeleteFromCollectionIfMatch(collectionName, id, etag string) error {
+	return r.DeleteFromCollectionIfMatchContext(context.Background(), collectionName, id, etag)
+}
+
+// DeleteFromCollectionIfMatchContext is like DeleteFromCollectionIfMatch but uses ctx for the request.
+func (r *ResourcesService) DeleteFromCollectionIfMatchContext(ctx context.Context, collectionName, id, etag string) error {
+	if etag == "" {
+		return errMissingETag
+	}
+	req, err := r.ResourceRequestContext(ctx, "DELETE", "application/json", collectionName, id, nil)
+	if err == nil {
+		req.Header.Set("If-Match", etag)
+	}
+	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
+	return err
+}
+
+// ModifyInCollection gets the resource into resource, which must be a
+// pointer, calls modify to change it and updates it only if it was not
+// modified meanwhile. If it was, it starts again with the new version of the
+// resource, up to 5 times. An error returned by modify stops it without
+// updating the resource.
+//
+//	var book Book
+//	err := client.Resources.ModifyInCollection("books:Book", "123", &book, func() error {
+//		book.Stock--
+//		return nil
+//	})
+func (r *ResourcesService) ModifyInCollection(collectionName, id string, resource interface{}, modify func() error) error {
+	return r.ModifyInCollectionContext(context.Background(), collectionName, id, resource, modify)
+}
+
+// ModifyInCollectionContext is like ModifyInCollection but uses ctx for the requests.
+func (r *ResourcesService) ModifyInCollectionContext(ctx context.Context, collectionName, id string, resource interface{}, modify func() error) error {
+	value := reflect.ValueOf(resource)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errModifyNotPointer
+	}
+
+	var err error
+	for attempt := 0; attempt < modifyMaxAttempts; attempt++ {
+		// fields of a previous version must not survive in the new one
+		value.Elem().Set(reflect.Zero(value.Elem().Type()))
+
+		var etag string
+		if etag, err = r.GetFromCollectionETagContext(ctx, collectionName, id, resource); err != nil {
+			return err
+		}
+		if err = modify(); err != nil {
+			return err
+		}
+		err = r.UpdateInCollectionIfMatchContext(ctx, collectionName, id, etag, resource)
+		if !errors.Is(err, ErrPreconditionFailed) {
+			return err
+		}
+		r.client.logger.Debugf("resource %s/%s modified concurrently, trying again", collectionName, id)
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package corbel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+type etagTestCounter struct {
+	Count int    `json:"count"`
+	Note  string `json:"note,omitempty"`
+}
+
+// newETagTestClient returns a client whose server has a counter resource
+// versioned with ETags. concurrent is called before every update, so tests
+// can change the resource meanwhile.
+func newETagTestClient(t *testing.T, counter *etagTestCounter, version *int, concurrent func()) *Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1.0/resource/test:Counter/1" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+			return
+		}
+		etag := fmt.Sprintf(`"v%d"`, *version)
+		switch r.Method {
+		case "GET":
+			w.Header().Set("ETag", etag)
+			json.NewEncoder(w).Encode(counter)
+			return
+		case "PUT":
+			concurrent()
+			etag = fmt.Sprintf(`"v%d"`, *version)
+		}
+		if r.Header.Get("If-Match") != etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if r.Method == "PUT" {
+			body, _ := io.ReadAll(r.Body)
+			*counter = etagTestCounter{}
+			json.Unmarshal(body, counter)
+			*version++
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestResourcesETagOffline(t *testing.T) {
+	counter, version := etagTestCounter{Count: 1}, 1
+	client := newETagTestClient(t, &counter, &version, func() {})
+
+	var got etagTestCounter
+	etag, err := client.Resources.GetFromCollectionETag("test:Counter", "1", &got)
+	if err != nil {
+		t.Fatalf("GetFromCollectionETag returned error: %v", err)
+	}
+	if etag != `"v1"` || got.Count != 1 {
+		t.Errorf("Error in GetFromCollectionETag. Got: %v %v, Want: \"v1\" 1", etag, got.Count)
+	}
+
+	got.Count = 2
+	if err := client.Resources.UpdateInCollectionIfMatch("test:Counter", "1", etag, &got); err != nil {
+		t.Fatalf("UpdateInCollectionIfMatch returned error: %v", err)
+	}
+	err = client.Resources.UpdateInCollectionIfMatch("test:Counter", "1", etag, &got)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("UpdateInCollectionIfMatch with an old ETag must fail with ErrPreconditionFailed. Got: %v", err)
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Errorf("A failed precondition must not be a conflict. Got: %v", err)
+	}
+	if err := client.Resources.DeleteFromCollectionIfMatch("test:Counter", "1", etag); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("DeleteFromCollectionIfMatch with an old ETag must fail with ErrPreconditionFailed. Got: %v", err)
+	}
+	if err := client.Resources.DeleteFromCollectionIfMatch("test:Counter", "1", `"v2"`); err != nil {
+		t.Errorf("DeleteFromCollectionIfMatch returned error: %v", err)
+	}
+	if err := client.Resources.UpdateInCollectionIfMatch("test:Counter", "1", "", &got); err != errMissingETag {
+		t.Errorf("UpdateInCollectionIfMatch without ETag must fail. Got: %v", err)
+	}
+}
+
+func TestResourcesModifyInCollectionOffline(t *testing.T) {
+	counter, version := etagTestCounter{Count: 1, Note: "first"}, 1
+	updates := 0
+	client := newETagTestClient(t, &counter, &version, func() {
+		// another worker updates the resource before the first two updates
+		if updates++; updates <= 2 {
+			counter = etagTestCounter{Count: counter.Count + 10}
+			version++
+		}
+	})
+
+	var got etagTestCounter
+	calls := 0
+	err := client.Resources.ModifyInCollection("test:Counter", "1", &got, func() error {
+		calls++
+		got.Count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ModifyInCollection returned error: %v", err)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("Error in modify calls. Got: %v, Want: %v", got, want)
+	}
+	if got, want := counter, (etagTestCounter{Count: 22}); got != want {
+		t.Errorf("Error in modified resource. Got: %v, Want: %v", got, want)
+	}
+
+	errModify := errors.New("modify failed")
+	err = client.Resources.ModifyInCollection("test:Counter", "1", &got, func() error {
+		return errModify
+	})
+	if err != errModify {
+		t.Errorf("ModifyInCollection must return the error of modify. Got: %v", err)
+	}
+
+	updates = -10
+	err = client.Resources.ModifyInCollection("test:Counter", "1", &got, func() error { return nil })
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("ModifyInCollection must give up after %d attempts. Got: %v", modifyMaxAttempts, err)
+	}
+
+	err = client.Resources.ModifyInCollection("test:Counter", "1", got, func() error { return nil })
+	if got, want := err, errModifyNotPointer; got != want {
+		t.Errorf("Error in ModifyInCollection without pointer. Got: %v, Want: %v", got, want)
+	}
+}
@@ -1,6 +1,9 @@
 package corbel
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // RelationData is a basic structure of data relations. By default this are the simplest
 // data stored in a relation, but since it's possible to add specific data to the relation
@@ -16,12 +19,44 @@ type RelationData struct {
 // with the _related_ resource. Additionally arbitrary information can be passed
 // to as relation data or nil.
 func (r *ResourcesService) AddRelation(collectionName, resourceID, relationName, relatedCollectionName, relatedID string, relationInfo interface{}) (string, error) {
-	req, err := r.RelationRequest("PUT", "application/json", collectionName, resourceID, relationName, relatedCollectionName, relatedID, relationInfo)
+	return r.AddRelationContext(context.Background(), collectionName, resourceID, relationName, relatedCollectionName, relatedID, relationInfo)
+}
+
+// AddRelationContext is like AddRelation but uses ctx for the request.
+func (r *ResourcesService) AddRelationContext(ctx context.Context, collectionName, resourceID, relationName, relatedCollectionName, relatedID string, relationInfo interface{}) (string, error) {
+	req, err := r.RelationRequestContext(ctx, "PUT", "application/json", collectionName, resourceID, relationName, relatedCollectionName, relatedID, relationInfo)
 	return returnErrorHTTPSimple(r.client, req, err, 201)
 }
 
+// AddRelationCreated is like AddRelation but returns the parsed location of
+// the created relation. If fetched is not nil the relation data is also got
+// into it.
+func (r *ResourcesService) AddRelationCreated(collectionName, resourceID, relationName, relatedCollectionName, relatedID string, relationInfo, fetched interface{}) (*Created, error) {
+	return r.AddRelationCreatedContext(context.Background(), collectionName, resourceID, relationName, relatedCollectionName, relatedID, relationInfo, fetched)
+}
+
+// AddRelationCreatedContext is like AddRelationCreated but uses ctx for the requests.
+func (r *ResourcesService) AddRelationCreatedContext(ctx context.Context, collectionName, resourceID, relationName, relatedCollectionName, relatedID string, relationInfo, fetched interface{}) (*Created, error) {
+	location, err := r.AddRelationContext(ctx, collectionName, resourceID, relationName, relatedCollectionName, relatedID, relationInfo)
+	if err != nil {
+		return nil, err
+	}
+	created, err := parseCreated(location)
+	if err != nil || fetched == nil {
+		return created, err
+	}
+	req, err := r.RelationRequestContext(ctx, "GET", "application/json", collectionName, resourceID, relationName, relatedCollectionName, created.ID, nil)
+	_, err = returnErrorHTTPInterface(r.client, req, err, fetched, 200)
+	return created, err
+}
+
 // MoveRelation sets the required order of the related items on the relationship.
 func (r *ResourcesService) MoveRelation(collectionName, resourceID, relationName, relatedCollectionName, relatedID string, order int) (string, error) {
+	return r.MoveRelationContext(context.Background(), collectionName, resourceID, relationName, relatedCollectionName, relatedID, order)
+}
+
+// MoveRelationContext is like MoveRelation but uses ctx for the request.
+func (r *ResourcesService) MoveRelationContext(ctx context.Context, collectionName, resourceID, relationName, relatedCollectionName, relatedID string, order int) (string, error) {
 	type orderRelation struct {
 		Order string `json:"_order"`
 	}
@@ -30,21 +65,31 @@ func (r *ResourcesService) MoveRelation(collectionName, resourceID, relationName
 		Order: fmt.Sprintf("$pos(%d)", order),
 	}
 
-	req, err := r.client.NewRequest("PUT", "resources", fmt.Sprintf("/v1.0/resource/%s/%s/%s;r=%s/%s", collectionName, resourceID, relationName, relatedCollectionName, relatedID), orderStruct)
+	req, err := r.client.NewRequestContext(ctx, "PUT", "resources", fmt.Sprintf("/v1.0/resource/%s/%s/%s;r=%s/%s", collectionName, resourceID, relationName, relatedCollectionName, relatedID), orderStruct)
 	return returnErrorHTTPSimple(r.client, req, err, 204)
 }
 
 // DeleteRelation deletes the desired relation between the origin and the related
 // resource
 func (r *ResourcesService) DeleteRelation(collectionName, resourceID, relationName, relatedCollectionName, relatedID string) error {
-	req, err := r.RelationRequest("DELETE", "application/json", collectionName, resourceID, relationName, relatedCollectionName, relatedID, nil)
+	return r.DeleteRelationContext(context.Background(), collectionName, resourceID, relationName, relatedCollectionName, relatedID)
+}
+
+// DeleteRelationContext is like DeleteRelation but uses ctx for the request.
+func (r *ResourcesService) DeleteRelationContext(ctx context.Context, collectionName, resourceID, relationName, relatedCollectionName, relatedID string) error {
+	req, err := r.RelationRequestContext(ctx, "DELETE", "application/json", collectionName, resourceID, relationName, relatedCollectionName, relatedID, nil)
 	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
 	return err
 }
 
 // DeleteAllRelations deletes all the relations by relationName of the desired resource
 func (r *ResourcesService) DeleteAllRelations(collectionName, resourceID, relationName string) error {
-	req, err := r.RelationRequest("DELETE", "application/json", collectionName, resourceID, relationName, "", "", nil)
+	return r.DeleteAllRelationsContext(context.Background(), collectionName, resourceID, relationName)
+}
+
+// DeleteAllRelationsContext is like DeleteAllRelations but uses ctx for the request.
+func (r *ResourcesService) DeleteAllRelationsContext(ctx context.Context, collectionName, resourceID, relationName string) error {
+	req, err := r.RelationRequestContext(ctx, "DELETE", "application/json", collectionName, resourceID, relationName, "", "", nil)
 	_, err = returnErrorHTTPSimple(r.client, req, err, 204)
 	return err
 }
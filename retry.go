@@ -0,0 +1,162 @@
+package corbel
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy defines how requests that failed because of connection errors
+// or transient server errors are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent, including
+	// the first one. Values lower than 2 disable retries.
+	MaxAttempts int
+
+	// MinBackoff is the time to wait before the first retry. It is doubled on
+	// every new attempt and a random jitter is applied.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum time to wait between attempts, even if the
+	// platform asks for a longer one using the Retry-After header.
+	MaxBackoff time.Duration
+
+	// RetryStatusCodes are the response status codes that are retried.
+	// If empty 429, 502, 503 and 504 are retried.
+	RetryStatusCodes []int
+
+	// RetryNonIdempotent allows to retry POST and PATCH requests, which are
+	// not retried by default since they could be applied twice.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with up to 3 attempts waiting
+// between 100ms and 5s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  100 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+	}
+}
+
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retryable looks if the request must be sent again after getting res or err
+func (p *RetryPolicy) retryable(req *http.Request, res *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "POST", "PATCH":
+		if !p.RetryNonIdempotent {
+			return false
+		}
+	}
+	if err != nil {
+		return !isContextError(err)
+	}
+
+	codes := p.RetryStatusCodes
+	if len(codes) == 0 {
+		codes = defaultRetryStatusCodes
+	}
+	for _, code := range codes {
+		if res.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the time to wait before sending attempt+1
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if wait, ok := retryAfter(res); ok {
+			if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+				return p.MaxBackoff
+			}
+			return wait
+		}
+	}
+
+	wait := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	// half of the wait is random to avoid clients retrying at the same time
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// retryAfter parses the Retry-After header of the response, that can be
+// either a number of seconds or a date
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// send sends the request retrying it as defined by the RetryPolicy of the
+// client
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	policy := c.RetryPolicy
+	for attempt := 1; ; attempt++ {
+		res, err := c.httpClient.Do(req)
+		if policy == nil || attempt >= policy.MaxAttempts || !policy.retryable(req, res, err) {
+			return res, err
+		}
+
+		wait := policy.backoff(attempt, res)
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+			c.logger.Debugf("retrying request in %v: %s", wait, res.Status)
+		} else {
+			c.logger.Debugf("retrying request in %v: %v", wait, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		next := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if next.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		req = next
+	}
+}
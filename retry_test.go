@@ -0,0 +1,116 @@
+package corbel
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyIdempotent(t *testing.T) {
+	var calls int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":"id"}`))
+	}))
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+
+	var resource map[string]interface{}
+	if err := client.Resources.GetFromCollection("test:Collection", "id", &resource); err != nil {
+		t.Errorf("GetFromCollection must be retried. Got: %v", err)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("Requests sent are %v, but want %v", got, want)
+	}
+}
+
+func TestRetryPolicyNonIdempotent(t *testing.T) {
+	var (
+		calls  int
+		bodies []string
+	)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Location", "http://localhost/v1.0/resource/test:Collection/id")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+
+	if _, err := client.Resources.AddToCollection("test:Collection", map[string]string{"key": "value"}); err == nil {
+		t.Errorf("AddToCollection must not be retried by default")
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("Requests sent are %v, but want %v", got, want)
+	}
+
+	calls, bodies = 0, nil
+	client.RetryPolicy.RetryNonIdempotent = true
+	if _, err := client.Resources.AddToCollection("test:Collection", map[string]string{"key": "value"}); err != nil {
+		t.Errorf("AddToCollection must be retried with RetryNonIdempotent. Got: %v", err)
+	}
+	if got, want := len(bodies), 2; got != want {
+		t.Fatalf("Requests sent are %v, but want %v", got, want)
+	}
+	if got, want := bodies[1], bodies[0]; got != want {
+		t.Errorf("Retried body is %v, but want %v", got, want)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 5, MinBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	for attempt, max := range []time.Duration{100, 200, 300, 300} {
+		max *= time.Millisecond
+		wait := policy.backoff(attempt+1, nil)
+		if wait < max/2 || wait > max {
+			t.Errorf("Backoff of attempt %d is %v, but want between %v and %v", attempt+1, wait, max/2, max)
+		}
+	}
+
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set("Retry-After", "0")
+	if got, want := policy.backoff(1, res), time.Duration(0); got != want {
+		t.Errorf("Backoff with Retry-After is %v, but want %v", got, want)
+	}
+	res.Header.Set("Retry-After", "120")
+	if got, want := policy.backoff(1, res), policy.MaxBackoff; got != want {
+		t.Errorf("Backoff with Retry-After is %v, but want %v", got, want)
+	}
+}
+
+func TestRetryPolicyOauthTokenUpgrade(t *testing.T) {
+	var calls int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch {
+		case calls < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case calls == 3:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+
+	if err := client.IAM.OauthTokenUpgrade("assets"); err != nil {
+		t.Errorf("OauthTokenUpgrade must be retried. Got: %v", err)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("Requests sent are %v, but want %v", got, want)
+	}
+	if err := client.IAM.OauthTokenUpgrade("assets"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("OauthTokenUpgrade with an invalid token. Got: %v, Want: %v", err, ErrUnauthorized)
+	}
+}
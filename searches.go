@@ -1,47 +1,63 @@
 package corbel
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // Search is the struct used to query every searcheable api in the platform
 type Search struct {
-	client   *Client
-	Query    *apiquery
-	Sort     *sort
-	PageSize int
-	endpoint string
-	baseURL  string
+	client     *Client
+	Query      *apiquery
+	conditions Conditions
+	fields     []string
+	distinct   []string
+	text       *textSearch
+	Sort       *sort
+	PageSize   int
+	endpoint   string
+	baseURL    string
 }
 
 // Page fills the struct array passed as parameter as paged search by pageNumber
 func (s *Search) Page(pageNumber int, result interface{}) error {
+	return s.PageContext(context.Background(), pageNumber, result)
+}
+
+// PageContext is like Page but uses ctx for the request.
+func (s *Search) PageContext(ctx context.Context, pageNumber int, result interface{}) error {
+	if err := s.Sort.validate(); err != nil {
+		return err
+	}
 	opts := &SearchListOptions{
-		APIQuery:    s.Query.string(),
+		APIQuery:    s.apiQuery(),
+		APISearch:   s.text.string(),
 		APISort:     s.Sort.string(),
 		APIPage:     pageNumber,
 		APIPageSize: s.PageSize,
+		APISelect:   strings.Join(s.fields, ","),
+		APIDistinct: strings.Join(s.distinct, ","),
 	}
-	req, err := s.client.NewRequest("GET", s.endpoint, s.queryString(opts), nil)
+	req, err := s.client.NewRequestContext(ctx, "GET", s.endpoint, s.queryString(opts), nil)
 	_, err = returnErrorHTTPInterface(s.client, req, err, result, 200)
 	return err
 }
 
 // Count returns the aggregated count of an especific field in the search
 func (s *Search) Count(field string) (int, error) {
+	return s.CountContext(context.Background(), field)
+}
+
+// CountContext is like Count but uses ctx for the request.
+func (s *Search) CountContext(ctx context.Context, field string) (int, error) {
 	var aggrCount struct {
 		Count int `json:"count"`
 	}
 
-	opts := &SearchListOptions{
-		APIQuery:       s.Query.string(),
-		APISort:        s.Sort.string(),
-		APIAggregation: fmt.Sprintf("{\"$count\":\"%s\"}", field),
-	}
-	req, err := s.client.NewRequest("GET", s.endpoint, s.queryString(opts), nil)
-	_, err = returnErrorHTTPInterface(s.client, req, err, &aggrCount, 200)
-	if err != nil {
+	if err := s.aggregate(ctx, "$count", field, &aggrCount); err != nil {
 		return 0, err
 	}
 	return aggrCount.Count, nil
@@ -50,22 +66,26 @@ func (s *Search) Count(field string) (int, error) {
 // CountAll returns the aggregated count of all items in the search.
 // It's an alias of Count("*")
 func (s *Search) CountAll() (int, error) {
-	return s.Count("*")
+	return s.CountAllContext(context.Background())
+}
+
+// CountAllContext is like CountAll but uses ctx for the request.
+func (s *Search) CountAllContext(ctx context.Context) (int, error) {
+	return s.CountContext(ctx, "*")
 }
 
 // Average returns the average of an especific field in the search
 func (s *Search) Average(field string) (float64, error) {
+	return s.AverageContext(context.Background(), field)
+}
+
+// AverageContext is like Average but uses ctx for the request.
+func (s *Search) AverageContext(ctx context.Context, field string) (float64, error) {
 	var aggrAvg struct {
 		Average float64 `json:"average"`
 	}
 
-	opts := &SearchListOptions{
-		APIQuery:       s.Query.string(),
-		APISort:        s.Sort.string(),
-		APIAggregation: fmt.Sprintf("{\"$avg\":\"%s\"}", field),
-	}
-	req, err := s.client.NewRequest("GET", s.endpoint, s.queryString(opts), nil)
-	if _, err = returnErrorHTTPInterface(s.client, req, err, &aggrAvg, 200); err != nil {
+	if err := s.aggregate(ctx, "$avg", field, &aggrAvg); err != nil {
 		return 0, err
 	}
 	return aggrAvg.Average, nil
@@ -79,30 +99,209 @@ func (s *Search) Average(field string) (float64, error) {
 
 // Sum returns the average of an especific field in the search as float
 func (s *Search) Sum(field string) (float64, error) {
+	return s.SumContext(context.Background(), field)
+}
+
+// SumContext is like Sum but uses ctx for the request.
+func (s *Search) SumContext(ctx context.Context, field string) (float64, error) {
 	var aggrSum struct {
 		Sum float64 `json:"sum"`
 	}
 
-	opts := &SearchListOptions{
-		APIQuery:       s.Query.string(),
-		APISort:        s.Sort.string(),
-		APIAggregation: fmt.Sprintf("{\"$sum\":\"%s\"}", field),
-	}
-	req, err := s.client.NewRequest("GET", s.endpoint, s.queryString(opts), nil)
-	if _, err = returnErrorHTTPInterface(s.client, req, err, &aggrSum, 200); err != nil {
+	if err := s.aggregate(ctx, "$sum", field, &aggrSum); err != nil {
 		return 0, err
 	}
 	return aggrSum.Sum, nil
 }
 
+// Max returns the maximum value of an especific field in the search
+func (s *Search) Max(field string) (float64, error) {
+	return s.MaxContext(context.Background(), field)
+}
+
+// MaxContext is like Max but uses ctx for the request.
+func (s *Search) MaxContext(ctx context.Context, field string) (float64, error) {
+	var aggrMax struct {
+		Max float64 `json:"max"`
+	}
+
+	if err := s.aggregate(ctx, "$max", field, &aggrMax); err != nil {
+		return 0, err
+	}
+	return aggrMax.Max, nil
+}
+
+// Min returns the minimum value of an especific field in the search
+func (s *Search) Min(field string) (float64, error) {
+	return s.MinContext(context.Background(), field)
+}
+
+// MinContext is like Min but uses ctx for the request.
+func (s *Search) MinContext(ctx context.Context, field string) (float64, error) {
+	var aggrMin struct {
+		Min float64 `json:"min"`
+	}
+
+	if err := s.aggregate(ctx, "$min", field, &aggrMin); err != nil {
+		return 0, err
+	}
+	return aggrMin.Min, nil
+}
+
+// Histogram returns how many items of the search have each value of field
+func (s *Search) Histogram(field string) ([]AggregationBucket, error) {
+	return s.HistogramContext(context.Background(), field)
+}
+
+// HistogramContext is like Histogram but uses ctx for the request.
+func (s *Search) HistogramContext(ctx context.Context, field string) ([]AggregationBucket, error) {
+	var buckets []AggregationBucket
+	if err := s.aggregate(ctx, "$histogram", field, &buckets); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// GroupBy returns how many items of the search have each combination of
+// values of fields
+func (s *Search) GroupBy(fields ...string) ([]AggregationBucket, error) {
+	return s.GroupByContext(context.Background(), fields...)
+}
+
+// GroupByContext is like GroupBy but uses ctx for the request.
+func (s *Search) GroupByContext(ctx context.Context, fields ...string) ([]AggregationBucket, error) {
+	if len(fields) == 0 {
+		return nil, errAggregationNoFields
+	}
+	var buckets []AggregationBucket
+	if err := s.aggregate(ctx, "$groupBy", fields, &buckets); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// AggregationBucket is a group of items returned by Histogram and GroupBy.
+// Values has the value of every field used to group the items.
+type AggregationBucket struct {
+	Values map[string]interface{}
+	Count  int
+}
+
+// UnmarshalJSON decodes a bucket, where every key other than count is the
+// value of a grouped field
+func (b *AggregationBucket) UnmarshalJSON(data []byte) error {
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if count, ok := values["count"].(float64); ok {
+		b.Count = int(count)
+	}
+	delete(values, "count")
+	b.Values = values
+	return nil
+}
+
+// aggregation returns the api:aggregation document for operator over value
+func aggregation(operator string, value interface{}) string {
+	apiAggregationString, _ := json.Marshal(map[string]interface{}{operator: value})
+	return string(apiAggregationString)
+}
+
+// aggregate requests the aggregation operator over value of the search and
+// decodes the response into result
+func (s *Search) aggregate(ctx context.Context, operator string, value interface{}, result interface{}) error {
+	if err := s.Sort.validate(); err != nil {
+		return err
+	}
+	opts := &SearchListOptions{
+		APIQuery:       s.apiQuery(),
+		APISearch:      s.text.string(),
+		APISort:        s.Sort.string(),
+		APIAggregation: aggregation(operator, value),
+	}
+	req, err := s.client.NewRequestContext(ctx, "GET", s.endpoint, s.queryString(opts), nil)
+	_, err = returnErrorHTTPInterface(s.client, req, err, result, 200)
+	return err
+}
+
 // SearchListOptions specifies the optional parameters for searches supporting
 // paging and aggregation
 type SearchListOptions struct {
 	APIQuery       string `url:"api:query,omitempty"`
+	APISearch      string `url:"api:search,omitempty"`
 	APISort        string `url:"api:sort,omitempty"`
 	APIPageSize    int    `url:"api:pageSize,omitempty"`
 	APIPage        int    `url:"api:page,omitempty"`
 	APIAggregation string `url:"api:aggregation,omitempty"`
+	APISelect      string `url:"api:select,omitempty"`
+	APIDistinct    string `url:"api:distinct,omitempty"`
+}
+
+// Select limits the fields of the items returned by Page to the given ones
+func (s *Search) Select(fields ...string) *Search {
+	s.fields = fields
+	return s
+}
+
+// TextSearch makes the search match only the items containing text in any
+// of fields, or in any text field if none is given. It's combined with the
+// rest of conditions of the search.
+func (s *Search) TextSearch(text string, fields ...string) *Search {
+	s.text = &textSearch{Text: text, Fields: fields}
+	return s
+}
+
+// textSearch is the full text search of a Search
+type textSearch struct {
+	Text   string   `json:"text"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// string returns the api:search of the text search
+func (t *textSearch) string() string {
+	if t == nil || t.Text == "" {
+		return ""
+	}
+	apiSearchString, _ := json.Marshal(t)
+	return string(apiSearchString)
+}
+
+// Distinct makes Page return only one item for every distinct combination of
+// values of fields. Every item returned only has those fields.
+func (s *Search) Distinct(fields ...string) *Search {
+	s.distinct = fields
+	return s
+}
+
+// OrderBy adds keys to sort the results by, after the ones already added
+func (s *Search) OrderBy(keys ...SortKey) *Search {
+	s.Sort.By(keys...)
+	return s
+}
+
+// Where adds conditions built with the query builder to the search. They are
+// combined with the ones in Query, and all of them must be matched.
+func (s *Search) Where(conditions ...Condition) *Search {
+	s.conditions = append(s.conditions, conditions...)
+	return s
+}
+
+// apiQuery returns the api:query of the search joining Query and the
+// conditions added with Where
+func (s *Search) apiQuery() string {
+	if len(s.conditions) == 0 {
+		return s.Query.string()
+	}
+	var query []interface{}
+	if object := s.Query.object(); object != nil {
+		query = append(query, object)
+	}
+	for _, condition := range s.conditions.flatten() {
+		query = append(query, condition)
+	}
+	apiQueryString, _ := json.Marshal(query)
+	return string(apiQueryString)
 }
 
 func (s *Search) queryString(options *SearchListOptions) string {
@@ -139,11 +338,20 @@ type apiquery struct {
 // QueryString returns the query string to append to the url we are searching for.
 // api:query must be enclosed by []
 func (q *apiquery) string() string {
+	object := q.object()
+	if object == nil {
+		return ""
+	}
+	return fmt.Sprintf("[%s]", string(object))
+}
+
+// object returns the query as a single condition object or nil if empty
+func (q *apiquery) object() json.RawMessage {
 	apiQueryString, _ := json.Marshal(q)
 	if string(apiQueryString) == "{}" {
-		return ""
+		return nil
 	}
-	return fmt.Sprintf("[%s]", string(apiQueryString))
+	return apiQueryString
 }
 
 // NewQuery returns a New search struct
@@ -166,6 +374,14 @@ func newQuery() *apiquery {
 type sort struct {
 	Asc  []string
 	Desc []string
+	keys []SortKey
+}
+
+// SortKey is a field to sort the results by and its direction, SortAsc or
+// SortDesc
+type SortKey struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"`
 }
 
 // NewSort returns a Sort struct
@@ -173,20 +389,60 @@ func newSort() *sort {
 	return &sort{}
 }
 
-// QueryString returns the query string to append to the url we are sorting
-func (s *sort) string() string {
-	apiSortMap := make(map[string]string)
+// By adds keys to sort by after the ones already added. Results are sorted by
+// the first key, then by the second one and so on.
+func (s *sort) By(keys ...SortKey) {
+	s.keys = append(s.keys, keys...)
+}
+
+// sortKeys returns all the keys to sort by in order: the ones added with By
+// followed by the fields in Asc and the fields in Desc
+func (s *sort) sortKeys() []SortKey {
+	keys := append([]SortKey{}, s.keys...)
 	for _, field := range s.Asc {
-		apiSortMap[field] = "asc"
+		keys = append(keys, SortKey{field, SortAsc})
 	}
-
 	for _, field := range s.Desc {
-		apiSortMap[field] = "desc"
+		keys = append(keys, SortKey{field, SortDesc})
+	}
+	return keys
+}
+
+// validate checks that no field is sorted more than once and the directions
+func (s *sort) validate() error {
+	fields := make(map[string]bool)
+	for _, key := range s.sortKeys() {
+		if key.Direction != SortAsc && key.Direction != SortDesc {
+			return errInvalidSortDirection
+		}
+		if fields[key.Field] {
+			return errSortConflict
+		}
+		fields[key.Field] = true
 	}
+	return nil
+}
 
-	apiSortString, _ := json.Marshal(apiSortMap)
-	if string(apiSortString) == "{}" {
+// QueryString returns the query string to append to the url we are sorting.
+// Fields are serialized in the order they are sorted by.
+func (s *sort) string() string {
+	keys := s.sortKeys()
+	if len(keys) == 0 {
 		return ""
 	}
-	return string(apiSortString)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		field, _ := json.Marshal(key.Field)
+		direction, _ := json.Marshal(key.Direction)
+		buf.Write(field)
+		buf.WriteByte(':')
+		buf.Write(direction)
+	}
+	buf.WriteByte('}')
+	return buf.String()
 }
@@ -1,6 +1,13 @@
 package corbel
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
 
 func TestSearchesQueryStrings(t *testing.T) {
 	query := newQuery()
@@ -83,9 +90,48 @@ func TestSortsQueryStrings(t *testing.T) {
 
 	sort.Desc = []string{"other", "andAnother"}
 	queryString = sort.string()
-	if got, want := queryString, `{"andAnother":"desc","firstName":"asc","lastName":"asc","other":"desc"}`; got != want {
+	if got, want := queryString, `{"firstName":"asc","lastName":"asc","other":"desc","andAnother":"desc"}`; got != want {
 		t.Errorf("Error in search Query String: Got: %v, Want: %v", got, want)
 	}
+
+	sort = newSort()
+	sort.By(SortKey{"b", SortDesc}, SortKey{"a", SortAsc}, SortKey{"c", SortDesc})
+	sort.Asc = []string{"d"}
+	queryString = sort.string()
+	if got, want := queryString, `{"b":"desc","a":"asc","c":"desc","d":"asc"}`; got != want {
+		t.Errorf("Error in search Query String: Got: %v, Want: %v", got, want)
+	}
+	if err := sort.validate(); err != nil {
+		t.Errorf("Sort without conflicts must be valid. Got: %v", err)
+	}
+
+	sort.Desc = []string{"a"}
+	if err := sort.validate(); err != errSortConflict {
+		t.Errorf("Sort with a field sorted twice must be invalid. Got: %v", err)
+	}
+
+	sort = newSort()
+	sort.By(SortKey{"a", "ascending"})
+	if err := sort.validate(); err != errInvalidSortDirection {
+		t.Errorf("Sort with an unknown direction must be invalid. Got: %v", err)
+	}
+}
+
+func TestSortsConflictOffline(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("no request must be sent with a conflicting sort. Got: %s %s", r.Method, r.URL)
+	}))
+
+	search := client.Resources.SearchCollection("test:Collection").OrderBy(SortKey{"name", SortAsc})
+	search.Sort.Desc = []string{"name"}
+
+	var result []map[string]interface{}
+	if err := search.Page(0, &result); err != errSortConflict {
+		t.Errorf("Page must fail with a conflicting sort. Got: %v", err)
+	}
+	if _, err := search.CountAll(); err != errSortConflict {
+		t.Errorf("CountAll must fail with a conflicting sort. Got: %v", err)
+	}
 }
 
 func TestSearchQueryString(t *testing.T) {
@@ -362,3 +408,183 @@ func TestSearchPagingAndAggregation(t *testing.T) {
 	}
 
 }
+
+func TestSearchPageContextDeadline(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var result []map[string]interface{}
+	search := client.Resources.SearchCollection("test:Collection")
+	if err := search.PageContext(ctx, 0, &result); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PageContext must fail when the deadline is exceeded. Got: %v", err)
+	}
+}
+
+func TestSearchAggregationString(t *testing.T) {
+	tests := []struct {
+		operator string
+		value    interface{}
+		want     string
+	}{
+		{"$count", "*", `{"$count":"*"}`},
+		{"$max", `price"}`, `{"$max":"price\"}"}`},
+		{"$groupBy", []string{"a", "b"}, `{"$groupBy":["a","b"]}`},
+	}
+
+	for _, test := range tests {
+		if got, want := aggregation(test.operator, test.value), test.want; got != want {
+			t.Errorf("Error in aggregation string. Got: %v, Want: %v", got, want)
+		}
+	}
+}
+
+func TestSearchAggregationsOffline(t *testing.T) {
+	responses := map[string]string{
+		`{"$max":"price"}`:              `{"max":10.5}`,
+		`{"$min":"price"}`:              `{"min":-2}`,
+		`{"$histogram":"color"}`:        `[{"color":"red","count":2},{"color":"blue","count":1}]`,
+		`{"$groupBy":["color","size"]}`: `[{"color":"red","size":3,"count":4}]`,
+	}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Query().Get("api:aggregation")]
+		if !ok {
+			t.Errorf("Unexpected aggregation: %v", r.URL.Query().Get("api:aggregation"))
+		}
+		w.Write([]byte(response))
+	}))
+	search := client.Resources.SearchCollection("test:Collection")
+
+	if got, err := search.Max("price"); err != nil || got != 10.5 {
+		t.Errorf("Error in Max. Got: %v, %v, Want: 10.5", got, err)
+	}
+	if got, err := search.Min("price"); err != nil || got != -2 {
+		t.Errorf("Error in Min. Got: %v, %v, Want: -2", got, err)
+	}
+
+	buckets, err := search.Histogram("color")
+	if err != nil {
+		t.Fatalf("Histogram returned error: %v", err)
+	}
+	if got, want := len(buckets), 2; got != want {
+		t.Fatalf("Error in Histogram buckets. Got: %v, Want: %v", got, want)
+	}
+	if got, want := buckets[0].Values["color"], "red"; got != want {
+		t.Errorf("Error in Histogram bucket value. Got: %v, Want: %v", got, want)
+	}
+	if got, want := buckets[1].Count, 1; got != want {
+		t.Errorf("Error in Histogram bucket count. Got: %v, Want: %v", got, want)
+	}
+
+	buckets, err = search.GroupBy("color", "size")
+	if err != nil {
+		t.Fatalf("GroupBy returned error: %v", err)
+	}
+	if got, want := len(buckets), 1; got != want {
+		t.Fatalf("Error in GroupBy buckets. Got: %v, Want: %v", got, want)
+	}
+	if got, want := buckets[0].Count, 4; got != want {
+		t.Errorf("Error in GroupBy bucket count. Got: %v, Want: %v", got, want)
+	}
+	if got, want := len(buckets[0].Values), 2; got != want {
+		t.Errorf("Error in GroupBy bucket values. Got: %v, Want: %v", got, want)
+	}
+	if got, want := buckets[0].Values["size"], float64(3); got != want {
+		t.Errorf("Error in GroupBy bucket value. Got: %v, Want: %v", got, want)
+	}
+
+	if _, err := search.GroupBy(); err != errAggregationNoFields {
+		t.Errorf("GroupBy without fields must fail. Got: %v", err)
+	}
+}
+
+func TestSearchSelectAndDistinctOffline(t *testing.T) {
+	var selected, distinct string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		selected = r.URL.Query().Get("api:select")
+		distinct = r.URL.Query().Get("api:distinct")
+		w.Write([]byte(`[{"name":"test"}]`))
+	}))
+
+	var result []map[string]interface{}
+	search := client.Resources.SearchCollection("test:Collection").Select("name", "price")
+	if err := search.Page(0, &result); err != nil {
+		t.Fatalf("Page returned error: %v", err)
+	}
+	if got, want := selected, "name,price"; got != want {
+		t.Errorf("Error in api:select. Got: %v, Want: %v", got, want)
+	}
+	if got, want := distinct, ""; got != want {
+		t.Errorf("Error in api:distinct. Got: %v, Want: %v", got, want)
+	}
+
+	search = client.Resources.SearchRelation("test:Group", "12345", "test:Users").Distinct("country")
+	if err := search.Page(0, &result); err != nil {
+		t.Fatalf("Page returned error: %v", err)
+	}
+	if got, want := selected, ""; got != want {
+		t.Errorf("Error in api:select. Got: %v, Want: %v", got, want)
+	}
+	if got, want := distinct, "country"; got != want {
+		t.Errorf("Error in api:distinct. Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestSearchTextSearchOffline(t *testing.T) {
+	var requests []url.Values
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query())
+		if r.URL.Query().Get("api:aggregation") != "" {
+			w.Write([]byte(`{"count":1}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+
+	search := client.Resources.SearchCollection("test:Collection")
+	if got, want := search.text.string(), ""; got != want {
+		t.Errorf("Error in empty api:search. Got: %v, Want: %v", got, want)
+	}
+
+	search.TextSearch("blue shoes", "name", "description")
+	search.Query.Eq["brand"] = "corbel"
+	search.Sort.Asc = []string{"name"}
+	search.PageSize = 20
+
+	var result []map[string]interface{}
+	if err := search.Page(2, &result); err != nil {
+		t.Fatalf("Page returned error: %v", err)
+	}
+	if _, err := search.CountAll(); err != nil {
+		t.Fatalf("CountAll returned error: %v", err)
+	}
+
+	if got, want := len(requests), 2; got != want {
+		t.Fatalf("Error in requests sent. Got: %v, Want: %v", got, want)
+	}
+	for _, request := range requests {
+		if got, want := request.Get("api:search"), `{"text":"blue shoes","fields":["name","description"]}`; got != want {
+			t.Errorf("Error in api:search. Got: %v, Want: %v", got, want)
+		}
+		if got, want := request.Get("api:query"), `[{"$eq":{"brand":"corbel"}}]`; got != want {
+			t.Errorf("Error in api:query. Got: %v, Want: %v", got, want)
+		}
+		if got, want := request.Get("api:sort"), `{"name":"asc"}`; got != want {
+			t.Errorf("Error in api:sort. Got: %v, Want: %v", got, want)
+		}
+	}
+	if got, want := requests[0].Get("api:page"), "2"; got != want {
+		t.Errorf("Error in api:page. Got: %v, Want: %v", got, want)
+	}
+	if got, want := requests[0].Get("api:pageSize"), "20"; got != want {
+		t.Errorf("Error in api:pageSize. Got: %v, Want: %v", got, want)
+	}
+
+	search.TextSearch("shoes")
+	if got, want := search.text.string(), `{"text":"shoes"}`; got != want {
+		t.Errorf("Error in api:search without fields. Got: %v, Want: %v", got, want)
+	}
+}
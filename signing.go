@@ -0,0 +1,40 @@
+package corbel
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// isRSASigningMethod looks if the JWT signing method is one of the RSA ones
+func isRSASigningMethod(method string) bool {
+	return strings.HasPrefix(method, "RS")
+}
+
+// signingKey returns the key to sign the client assertions with. HMAC methods
+// use the client secret itself while RSA methods expect it to be a PEM encoded
+// private key.
+func signingKey(method, clientSecret string) (interface{}, error) {
+	if !isRSASigningMethod(method) {
+		return []byte(clientSecret), nil
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(clientSecret))
+	if err != nil {
+		return nil, errInvalidRSAPrivateKey
+	}
+	return key, nil
+}
+
+// RSAPrivateKeyFromFile reads the PEM encoded RSA private key in path to be
+// used as clientSecret by clients signing with RS256, RS384 or RS512.
+func RSAPrivateKeyFromFile(path string) (string, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err = jwt.ParseRSAPrivateKeyFromPEM(pem); err != nil {
+		return "", errInvalidRSAPrivateKey
+	}
+	return string(pem), nil
+}
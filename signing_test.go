@@ -0,0 +1,88 @@
+package corbel
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func newTestRSAKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return key, string(pem.EncodeToMemory(block))
+}
+
+func TestSigningRSA(t *testing.T) {
+	key, keyPEM := newTestRSAKey(t)
+
+	for _, method := range []string{"RS256", "RS384", "RS512", "RSA"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assertion, err := jwt.Parse(r.FormValue("assertion"), func(token *jwt.Token) (interface{}, error) {
+				if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+					return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+				}
+				return &key.PublicKey, nil
+			})
+			if err != nil || !assertion.Valid {
+				t.Errorf("%s assertion must be signed with the RSA key. Got: %v", method, err)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"accessToken":"token","expiresAt":%d}`, time.Now().Add(time.Hour).Unix()*1000)
+		}))
+
+		endpoints := map[string]string{"iam": server.URL}
+		client, err := NewClient(nil, endpoints, "someID", "", keyPEM, "", "", method, 3000, "info")
+		if err != nil {
+			t.Fatalf("NewClient must accept a PEM RSA key for %s. Got: %v", method, err)
+		}
+		if err = client.IAM.OauthToken(); err != nil {
+			t.Errorf("OauthToken must not fail for %s. Got: %v", method, err)
+		}
+		server.Close()
+	}
+}
+
+func TestSigningRSAInvalidKey(t *testing.T) {
+	if _, err := NewClient(nil, nil, "someID", "", "someSecret", "", "", "RS256", 3000, "info"); err != errInvalidRSAPrivateKey {
+		t.Errorf("NewClient must fail if the RSA key is not valid. Got: %v", err)
+	}
+}
+
+func TestSigningRSAPrivateKeyFromFile(t *testing.T) {
+	_, keyPEM := newTestRSAKey(t)
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "client.pem")
+	if err := ioutil.WriteFile(path, []byte(keyPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := RSAPrivateKeyFromFile(path)
+	if err != nil {
+		t.Errorf("RSAPrivateKeyFromFile must not fail. Got: %v", err)
+	}
+	if got, want := secret, keyPEM; got != want {
+		t.Errorf("RSAPrivateKeyFromFile is %v, but want %v", got, want)
+	}
+
+	path = filepath.Join(dir, "invalid.pem")
+	if err := ioutil.WriteFile(path, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = RSAPrivateKeyFromFile(path); err != errInvalidRSAPrivateKey {
+		t.Errorf("RSAPrivateKeyFromFile must fail with an invalid key. Got: %v", err)
+	}
+}
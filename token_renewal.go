@@ -0,0 +1,107 @@
+package corbel
+
+import (
+	"context"
+	"time"
+)
+
+// tokenRenewalRetry is the time to wait before trying again when there is no
+// token to renew or the renewal failed.
+const tokenRenewalRetry = time.Second
+
+// StartTokenRenewal starts a background loop that refreshes the current token
+// before duration ahead of its expiration, so long running clients never send
+// an stale token. If before is longer than half the lifetime of the renewed
+// tokens, they're renewed at half their lifetime instead. It does nothing if
+// the loop is already running.
+func (c *Client) StartTokenRenewal(before time.Duration) {
+	c.renewalMu.Lock()
+	defer c.renewalMu.Unlock()
+	if c.renewalStop != nil {
+		return
+	}
+	c.renewalStop = make(chan struct{})
+	c.renewalDone = make(chan struct{})
+	go c.renewToken(before, c.renewalStop, c.renewalDone)
+}
+
+// StopTokenRenewal stops the background token renewal and waits for it to
+// finish. It does nothing if the loop is not running.
+func (c *Client) StopTokenRenewal() {
+	c.renewalMu.Lock()
+	stop, done := c.renewalStop, c.renewalDone
+	c.renewalStop, c.renewalDone = nil, nil
+	c.renewalMu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// renewToken is the background token renewal loop
+func (c *Client) renewToken(before time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	wait, window := time.Duration(0), before
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		wait, window = c.renewTokenOnce(ctx, before, window)
+	}
+}
+
+// renewTokenOnce refreshes the token if it expires within window and returns
+// how long to wait until the next check and the window to use then, which is
+// before clamped to half the lifetime of the renewed token
+func (c *Client) renewTokenOnce(ctx context.Context, before, window time.Duration) (time.Duration, time.Duration) {
+	token, expiresAt := c.tokenExpiration()
+	if token == "" {
+		return tokenRenewalRetry, window
+	}
+	if wait := time.Until(expiresAt) - window; wait > 0 {
+		return wait, window
+	}
+
+	c.logger.Debug("renewing token")
+	if _, err := c.token(ctx, window); err != nil {
+		if ctx.Err() == nil {
+			c.logger.Warnf("failed to renew token: %v", err)
+		}
+		return tokenRenewalRetry, window
+	}
+
+	// tokens with a lifetime shorter than before must not be renewed in a loop
+	_, expiresAt = c.tokenExpiration()
+	lifetime := time.Until(expiresAt)
+	window = before
+	if window > lifetime/2 {
+		window = lifetime / 2
+	}
+	if wait := lifetime - window; wait > tokenRenewalRetry {
+		return wait, window
+	}
+	return tokenRenewalRetry, window
+}
+
+// tokenExpiration returns the current token and when it expires
+func (c *Client) tokenExpiration() (string, time.Time) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.CurrentToken, time.Unix(0, c.CurrentTokenExpiresAt*int64(time.Millisecond))
+}
@@ -0,0 +1,84 @@
+package corbel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenRenewal(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		expiresAt := time.Now().Add(time.Hour).Unix() * 1000
+		fmt.Fprintf(w, `{"accessToken":"renewed%d","expiresAt":%d,"refreshToken":"refresh"}`, n, expiresAt)
+	}))
+
+	client.CurrentToken = "current"
+	client.CurrentRefreshToken = "refresh"
+	client.CurrentTokenExpiresAt = time.Now().Add(time.Minute).Unix() * 1000
+
+	client.StartTokenRenewal(2 * time.Minute)
+	client.StartTokenRenewal(2 * time.Minute)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for token, _ := client.tokenExpiration(); token == "current" && time.Now().Before(deadline); token, _ = client.tokenExpiration() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	client.StopTokenRenewal()
+	client.StopTokenRenewal()
+
+	if got, want := atomic.LoadInt32(&calls), int32(1); got != want {
+		t.Fatalf("StartTokenRenewal must renew the token once. Got %v requests, want %v", got, want)
+	}
+	if got, want := client.Token(), "renewed1"; got != want {
+		t.Errorf("Token is %v, but want %v", got, want)
+	}
+}
+
+func TestTokenRenewalShortLived(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		expiresAt := time.Now().Add(time.Hour).Unix() * 1000
+		fmt.Fprintf(w, `{"accessToken":"renewed%d","expiresAt":%d,"refreshToken":"refresh"}`, n, expiresAt)
+	}))
+
+	client.CurrentToken = "current"
+	client.CurrentRefreshToken = "refresh"
+	client.CurrentTokenExpiresAt = time.Now().Add(time.Minute).Unix() * 1000
+
+	// tokens last less than before, so they must be renewed at half their
+	// lifetime instead of every retry
+	client.StartTokenRenewal(2 * time.Hour)
+	time.Sleep(3*tokenRenewalRetry + tokenRenewalRetry/2)
+	client.StopTokenRenewal()
+
+	if got, want := atomic.LoadInt32(&calls), int32(1); got != want {
+		t.Errorf("StartTokenRenewal must renew short lived tokens once. Got %v requests, want %v", got, want)
+	}
+}
+
+func TestTokenRefreshSkew(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expiresAt := time.Now().Add(time.Hour).Unix() * 1000
+		fmt.Fprintf(w, `{"accessToken":"refreshed","expiresAt":%d}`, expiresAt)
+	}))
+
+	client.CurrentToken = "current"
+	client.CurrentTokenExpiresAt = time.Now().Add(5*time.Second).Unix() * 1000
+
+	token, _ := client.TokenContext(context.Background())
+	if got, want := token, "current"; got != want {
+		t.Errorf("Token must not be refreshed before expiring. Got %v, want %v", got, want)
+	}
+
+	client.TokenRefreshSkew = 10 * time.Second
+	token, _ = client.TokenContext(context.Background())
+	if got, want := token, "refreshed"; got != want {
+		t.Errorf("Token must be refreshed within TokenRefreshSkew. Got %v, want %v", got, want)
+	}
+}
@@ -1,5 +1,10 @@
 package corbel
 
+import (
+	"context"
+	"errors"
+)
+
 // stringInSlice looks if a string is in a string array
 func stringInSlice(array []string, item string) bool {
 	for _, i := range array {
@@ -9,3 +14,8 @@ func stringInSlice(array []string, item string) bool {
 	}
 	return false
 }
+
+// isContextError looks if err has been caused by a canceled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}